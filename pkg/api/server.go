@@ -0,0 +1,323 @@
+package api
+
+import (
+	"crypto/rand"
+	"dicer/pkg/game"
+	"dicer/pkg/logger"
+	"dicer/pkg/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*************************************
+* Server
+*************************************/
+// Server hosts up to maxGames games over HTTP, dropping any left idle for
+// idleTTL. The map of games has its own lock, which also guards when each
+// game was last used, and each game is locked while a request reads or
+// changes it.
+type Server struct {
+	mu       sync.Mutex
+	games    map[string]*session
+	mux      *http.ServeMux
+	maxGames int           // No limit when 0
+	idleTTL  time.Duration // Games are kept forever when 0
+}
+
+type session struct {
+	mu       sync.Mutex
+	id       string
+	seed     uint64
+	game     *game.Game
+	lastUsed time.Time
+}
+
+// Games are small, but a server left running shouldn't keep every one
+const (
+	DEFAULT_MAX_GAMES = 1000
+	DEFAULT_IDLE_TTL  = 30 * time.Minute
+)
+
+// gameResponse is returned by every endpoint that acts on a game
+type gameResponse struct {
+	ID    string     `json:"id"`
+	Seed  uint64     `json:"seed"`
+	Rules game.Rules `json:"rules"`
+	game.State
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Pos   *int   `json:"pos,omitempty"`
+}
+
+// Rules left out of the request keep their defaults
+type createRequest struct {
+	Rules game.Rules `json:"rules"`
+	Seed  uint64     `json:"seed"`
+}
+
+type rerollRequest struct {
+	Dice []int `json:"dice"` // Positions counted from 1
+}
+
+// Item and Die are counted from 1, and Die and Value are only needed by
+// items that ask for them
+type useRequest struct {
+	Item  int `json:"item"`
+	Die   int `json:"die"`
+	Value int `json:"value"`
+}
+
+type submitRequest struct {
+	Expression string `json:"expression"`
+}
+
+// Request bodies are tiny, so anything bigger is a mistake
+const MAX_BODY_BYTES = 4096
+
+func NewServer() *Server {
+	return NewServerWithLimits(DEFAULT_MAX_GAMES, DEFAULT_IDLE_TTL)
+}
+
+func NewServerWithLimits(maxGames int, idleTTL time.Duration) *Server {
+	s := &Server{
+		games:    make(map[string]*session),
+		mux:      http.NewServeMux(),
+		maxGames: maxGames,
+		idleTTL:  idleTTL,
+	}
+
+	s.mux.HandleFunc("POST /games", s.handleCreate)
+	s.mux.HandleFunc("GET /games/{id}", s.withSession(s.handleState))
+	s.mux.HandleFunc("GET /games/{id}/history", s.withSession(s.handleHistory))
+	s.mux.HandleFunc("POST /games/{id}/roll", s.withSession(s.handleRoll))
+	s.mux.HandleFunc("POST /games/{id}/reroll", s.withSession(s.handleReroll))
+	s.mux.HandleFunc("POST /games/{id}/use", s.withSession(s.handleUse))
+	s.mux.HandleFunc("POST /games/{id}/submit", s.withSession(s.handleSubmit))
+	s.mux.HandleFunc("POST /games/{id}/challenge", s.withSession(s.handleChallenge))
+	s.mux.HandleFunc("POST /games/{id}/continue", s.withSession(s.handleContinue))
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+/*************************************
+* Handlers
+*************************************/
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{Rules: game.DefaultRules()}
+	if r.ContentLength != 0 && !decodeBody(w, r, &req) {
+		return
+	}
+
+	if err := req.Rules.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	seed := req.Seed
+	if seed == 0 {
+		seed = models.NewSeed()
+	}
+
+	now := time.Now()
+	sess := &session{
+		id:       newID(),
+		seed:     seed,
+		game:     game.CreateGameWithRules(req.Rules, models.CreateSeededSource(seed)),
+		lastUsed: now,
+	}
+
+	s.mu.Lock()
+	nextExpiry := s.evictIdle(now)
+	if s.maxGames > 0 && len(s.games) >= s.maxGames {
+		s.mu.Unlock()
+		if s.idleTTL > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((nextExpiry+time.Second-1)/time.Second)))
+		}
+		writeError(w, http.StatusServiceUnavailable, errors.New("too many games in progress, try again later"))
+		return
+	}
+	s.games[sess.id] = sess
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, sess.response())
+}
+
+// withSession finds the game named in the path and holds its lock while
+// handler runs
+func (s *Server) withSession(handler func(http.ResponseWriter, *http.Request, *session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		s.mu.Lock()
+		sess, ok := s.games[r.PathValue("id")]
+		if ok && s.isIdle(sess, now) {
+			delete(s.games, sess.id)
+			ok = false
+		}
+		if ok {
+			sess.lastUsed = now
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("no game with that id"))
+			return
+		}
+
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		handler(w, r, sess)
+	}
+}
+
+func (s *Server) isIdle(sess *session, now time.Time) bool {
+	return s.idleTTL > 0 && now.Sub(sess.lastUsed) >= s.idleTTL
+}
+
+// evictIdle drops games that have sat idle too long, returning how long until
+// the next one will have. s.mu must be held.
+func (s *Server) evictIdle(now time.Time) time.Duration {
+	next := s.idleTTL
+	for id, sess := range s.games {
+		if s.isIdle(sess, now) {
+			delete(s.games, id)
+			logger.Logger().Debug("dropped idle game", "id", id, "idle", now.Sub(sess.lastUsed).Round(time.Second))
+			continue
+		}
+		next = min(next, s.idleTTL-now.Sub(sess.lastUsed))
+	}
+	return next
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request, sess *session) {
+	writeJSON(w, http.StatusOK, sess.response())
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request, sess *session) {
+	history := sess.game.History
+	if history == nil {
+		history = []game.TurnRecord{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handleRoll(w http.ResponseWriter, r *http.Request, sess *session) {
+	sess.respond(w, sess.game.Roll())
+}
+
+func (s *Server) handleReroll(w http.ResponseWriter, r *http.Request, sess *session) {
+	var req rerollRequest
+	if r.ContentLength != 0 && !decodeBody(w, r, &req) {
+		return
+	}
+
+	selected := make(map[int]struct{})
+	for _, num := range req.Dice {
+		selected[num-1] = struct{}{}
+	}
+	sess.respond(w, sess.game.Reroll(selected))
+}
+
+func (s *Server) handleUse(w http.ResponseWriter, r *http.Request, sess *session) {
+	var req useRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	sess.respond(w, sess.game.UseItem(req.Item-1, models.ItemUse{Die: req.Die - 1, Value: req.Value}))
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request, sess *session) {
+	var req submitRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	sess.respond(w, sess.game.Submit(req.Expression))
+}
+
+func (s *Server) handleChallenge(w http.ResponseWriter, r *http.Request, sess *session) {
+	sess.respond(w, sess.game.Challenge())
+}
+
+func (s *Server) handleContinue(w http.ResponseWriter, r *http.Request, sess *session) {
+	sess.respond(w, sess.game.NextTurn())
+}
+
+/*************************************
+* Responses
+*************************************/
+func (sess *session) response() gameResponse {
+	return gameResponse{
+		ID:    sess.id,
+		Seed:  sess.seed,
+		Rules: sess.game.Rules,
+		State: sess.game.State(),
+	}
+}
+
+// respond writes the game after an action, or why the action was refused
+func (sess *session) respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sess.response())
+}
+
+func errorStatus(err error) int {
+	var phaseErr *game.PhaseError
+	var expErr *game.ExpressionError
+
+	switch {
+	case errors.Is(err, game.ErrGameOver), errors.As(err, &phaseErr):
+		return http.StatusConflict
+	case errors.As(err, &expErr):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MAX_BODY_BYTES))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Logger().Warn("couldn't write response", "status", status, "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := errorResponse{Error: err.Error()}
+
+	var expErr *game.ExpressionError
+	if errors.As(err, &expErr) {
+		resp.Pos = &expErr.Pos
+	}
+
+	writeJSON(w, status, resp)
+}
+
+func newID() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
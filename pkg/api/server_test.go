@@ -0,0 +1,251 @@
+package api
+
+import (
+	"dicer/pkg/game"
+	"dicer/pkg/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// request sends body to the server and returns the status and the body it
+// wrote back
+func request(t testing.TB, s *Server, method, path, body string) (int, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+// expect sends body and fails unless the server answers with want, decoding
+// what it wrote into v when v isn't nil
+func expect(t testing.TB, s *Server, method, path, body string, want int, v any) {
+	t.Helper()
+	status, data := request(t, s, method, path, body)
+	if status != want {
+		t.Fatalf("%s %s returned %d, want %d: %s", method, path, status, want, data)
+	}
+	if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("%s %s wrote %s: %v", method, path, data, err)
+		}
+	}
+}
+
+// createGame starts a seeded game with the default rules
+func createGame(t testing.TB, s *Server) gameResponse {
+	t.Helper()
+	var created gameResponse
+	expect(t, s, "POST", "/games", `{"seed": 7}`, http.StatusCreated, &created)
+	return created
+}
+
+// sumOf adds up every die, which is always a valid expression
+func sumOf(dice []int) string {
+	parts := make([]string, len(dice))
+	for i, value := range dice {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, " + ")
+}
+
+func TestPlayTurn(t *testing.T) {
+	s := NewServer()
+	created := createGame(t, s)
+	if created.ID == "" || created.Seed != 7 || created.Phase != models.GS_TurnStart.String() {
+		t.Fatalf("created %+v, want an id, seed 7 and the start phase", created)
+	}
+	path := "/games/" + created.ID
+
+	var rolled gameResponse
+	expect(t, s, "POST", path+"/roll", "", http.StatusOK, &rolled)
+	if len(rolled.Dice) != game.DefaultRules().NumDice {
+		t.Fatalf("rolled %v, want %d dice", rolled.Dice, game.DefaultRules().NumDice)
+	}
+
+	var rerolled gameResponse
+	expect(t, s, "POST", path+"/reroll", `{"dice": [1, 2]}`, http.StatusOK, &rerolled)
+	if rerolled.Phase != models.GS_ExpressionPhase.String() {
+		t.Fatalf("after rerolling the phase is %s, want the expression phase", rerolled.Phase)
+	}
+
+	var state gameResponse
+	expect(t, s, "GET", path, "", http.StatusOK, &state)
+	if fmt.Sprint(state.Dice) != fmt.Sprint(rerolled.Dice) {
+		t.Fatalf("state has dice %v, want %v from the reroll", state.Dice, rerolled.Dice)
+	}
+
+	exp := sumOf(state.Dice)
+	body, _ := json.Marshal(submitRequest{Expression: exp})
+	var submitted gameResponse
+	expect(t, s, "POST", path+"/submit", string(body), http.StatusOK, &submitted)
+	if submitted.Phase != models.GS_ResultsPhase.String() || submitted.Result == nil {
+		t.Fatalf("after submitting the phase is %s with result %v, want the results phase and a result", submitted.Phase, submitted.Result)
+	}
+
+	var history []game.TurnRecord
+	expect(t, s, "GET", path+"/history", "", http.StatusOK, &history)
+	if len(history) != 1 || history[0].Expression != exp || history[0].Result == nil || *history[0].Result != *submitted.Result {
+		t.Fatalf("history is %+v, want the one turn that submitted %q", history, exp)
+	}
+
+	var next gameResponse
+	expect(t, s, "POST", path+"/continue", "", http.StatusOK, &next)
+	if next.Round != 2 && next.Phase != models.GS_GameOver.String() {
+		t.Fatalf("after continuing the round is %d in %s, want round 2", next.Round, next.Phase)
+	}
+}
+
+func TestSameSeedSameDice(t *testing.T) {
+	s := NewServer()
+	var dice []string
+	for range 2 {
+		created := createGame(t, s)
+		var rolled gameResponse
+		expect(t, s, "POST", "/games/"+created.ID+"/roll", "", http.StatusOK, &rolled)
+		dice = append(dice, fmt.Sprint(rolled.Dice))
+	}
+	if dice[0] != dice[1] {
+		t.Fatalf("seed 7 rolled %s then %s, want the same dice", dice[0], dice[1])
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string // Under a new game, unless it starts with /games
+		body    string
+		rolled  bool // Roll and keep every die first
+		want    int
+		wantPos bool
+	}{
+		{name: "unknown game", method: "GET", path: "/games/nope", want: http.StatusNotFound},
+		{name: "rules out of range", method: "POST", path: "/games", body: `{"rules": {"numDice": 0}}`, want: http.StatusBadRequest},
+		{name: "unknown field", method: "POST", path: "/games", body: `{"sed": 7}`, want: http.StatusBadRequest},
+		{name: "unknown field on an action", method: "POST", path: "/reroll", body: `{"dice": [], "all": true}`, want: http.StatusBadRequest},
+		{name: "not json", method: "POST", path: "/submit", body: `3 + 4`, rolled: true, want: http.StatusBadRequest},
+		{name: "oversized body", method: "POST", path: "/submit", body: `{"expression": "` + strings.Repeat("1", MAX_BODY_BYTES) + `"}`, rolled: true, want: http.StatusBadRequest},
+		{name: "wrong phase", method: "POST", path: "/submit", body: `{"expression": "1 + 2"}`, want: http.StatusConflict},
+		{name: "rolling twice", method: "POST", path: "/roll", rolled: true, want: http.StatusConflict},
+		{name: "invalid expression", method: "POST", path: "/submit", body: `{"expression": "1 +"}`, rolled: true, want: http.StatusUnprocessableEntity, wantPos: true},
+		{name: "no such die", method: "POST", path: "/reroll", body: `{"dice": [9]}`, want: http.StatusBadRequest},
+		{name: "items are off", method: "POST", path: "/use", body: `{"item": 1}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			path := tt.path
+			if !strings.HasPrefix(path, "/games") {
+				id := createGame(t, s).ID
+				path = "/games/" + id + path
+				expect(t, s, "POST", "/games/"+id+"/roll", "", http.StatusOK, nil)
+				if tt.rolled {
+					expect(t, s, "POST", "/games/"+id+"/reroll", "", http.StatusOK, nil)
+				}
+			}
+
+			var resp errorResponse
+			expect(t, s, tt.method, path, tt.body, tt.want, &resp)
+			if resp.Error == "" {
+				t.Errorf("the response has no error message")
+			}
+			if (resp.Pos != nil) != tt.wantPos {
+				t.Errorf("the response has pos %v, want a pos: %v", resp.Pos, tt.wantPos)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"game over", game.ErrGameOver, http.StatusConflict},
+		{"wrong phase", &game.PhaseError{}, http.StatusConflict},
+		{"wrapped phase", fmt.Errorf("roll: %w", &game.PhaseError{}), http.StatusConflict},
+		{"expression", &game.ExpressionError{Message: "missing a die"}, http.StatusUnprocessableEntity},
+		{"anything else", fmt.Errorf("there is no die 9"), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorStatus(tt.err); got != tt.want {
+				t.Errorf("errorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameLimit(t *testing.T) {
+	s := NewServerWithLimits(1, time.Hour)
+	createGame(t, s)
+
+	status, _ := request(t, s, "POST", "/games", "")
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("a second game returned %d, want %d", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestIdleGamesAreDropped(t *testing.T) {
+	s := NewServerWithLimits(1, 20*time.Millisecond)
+	first := createGame(t, s)
+	time.Sleep(30 * time.Millisecond)
+
+	// The idle game makes room for a new one, and is gone
+	createGame(t, s)
+	expect(t, s, "GET", "/games/"+first.ID, "", http.StatusNotFound, nil)
+}
+
+// Run with -race to check the locking
+func TestConcurrentRequests(t *testing.T) {
+	s := NewServer()
+	path := "/games/" + createGame(t, s).ID
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 100)
+	for i := range cap(statuses) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 4 {
+			case 0:
+				status, _ := request(t, s, "POST", path+"/roll", "")
+				statuses <- status
+			case 1:
+				status, _ := request(t, s, "GET", path, "")
+				statuses <- status
+			case 2:
+				status, _ := request(t, s, "GET", path+"/history", "")
+				statuses <- status
+			case 3:
+				status, _ := request(t, s, "POST", "/games", "")
+				statuses <- status
+			}
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	counts := make(map[int]int)
+	for status := range statuses {
+		counts[status]++
+	}
+
+	// Only one of the 25 rolls can start the turn
+	if counts[http.StatusConflict] != 24 {
+		t.Errorf("got statuses %v, want 24 rolls refused with %d", counts, http.StatusConflict)
+	}
+	if counts[http.StatusOK] != 51 || counts[http.StatusCreated] != 25 {
+		t.Errorf("got statuses %v, want 51 %d and 25 %d", counts, http.StatusOK, http.StatusCreated)
+	}
+}
@@ -1,8 +1,8 @@
 package config
 
 const (
-	NumDice             = 4
-	NumAilments         = 5
-	MaxLives            = 3
-	RemovedAilmentValue = -1
+	NumDice     = 4
+	DiceSides   = 6
+	NumAilments = 5
+	MaxLives    = 3
 )
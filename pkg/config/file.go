@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+/*************************************
+* Config File
+*************************************/
+// Settings are the user preferences read from config.toml
+type Settings struct {
+	Theme     string                       `toml:"theme"`
+	Themes    map[string]map[string]string `toml:"themes"`
+	KeyPreset string                       `toml:"key_preset"`
+	Keys      map[string][]string          `toml:"keys"`
+	Rules     string                       `toml:"rules"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/dicer/config.toml, falling back to
+// ~/.config when XDG_CONFIG_HOME isn't set.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dicer", "config.toml")
+}
+
+// StateDir returns $XDG_STATE_HOME/dicer, falling back to ~/.local/state
+// when XDG_STATE_HOME isn't set.
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "dicer")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state", "dicer")
+}
+
+// Load reads the settings at path. A missing file isn't an error and
+// results in empty settings.
+func Load(path string) (Settings, error) {
+	var settings Settings
+	if path == "" {
+		return settings, nil
+	}
+
+	_, err := toml.DecodeFile(path, &settings)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Settings{}, nil
+	}
+
+	return settings, err
+}
@@ -0,0 +1,233 @@
+package game
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/logger"
+	"dicer/pkg/models"
+	"errors"
+	mathrand "math/rand/v2"
+)
+
+/*************************************
+* Campaign
+*************************************/
+// Level is one game in a campaign, played with its own rules
+type Level struct {
+	Name        string
+	Description string
+	Rules       Rules
+}
+
+// CampaignLevels get harder as they go: wider ranges of ailments, fewer
+// operators and different dice. Every level hands out items.
+var CampaignLevels = []Level{
+	{
+		Name:        "Warm up",
+		Description: "Ailments 1 to 5",
+		Rules:       campaignRules(4, 6, 5, ""),
+	},
+	{
+		Name:        "Nine lives",
+		Description: "Ailments 1 to 9",
+		Rules:       campaignRules(4, 6, 9, ""),
+	},
+	{
+		Name:        "Adding up",
+		Description: "Ailments 1 to 9, with no multiplication",
+		Rules:       campaignRules(4, 6, 9, "+-/"),
+	},
+	{
+		Name:        "Big dice",
+		Description: "Ailments 1 to 12, rolled with eight-sided dice",
+		Rules:       campaignRules(4, 8, 12, ""),
+	},
+	{
+		Name:        "Handful",
+		Description: "Ailments 1 to 15, with five dice and no division",
+		Rules:       campaignRules(5, 6, 15, "+-*"),
+	},
+	{
+		Name:        "Gauntlet",
+		Description: "Ailments 1 to 18, some tough, cursed or spreading",
+		Rules: Rules{
+			NumDice:   5,
+			DiceSides: 6,
+			MaxLives:  config.MaxLives,
+			Items:     true,
+			Ailments:  gauntletAilments(18),
+		},
+	},
+}
+
+func campaignRules(numDice, sides, numAilments int, operators string) Rules {
+	return Rules{
+		NumDice:     numDice,
+		DiceSides:   sides,
+		NumAilments: numAilments,
+		MaxLives:    config.MaxLives,
+		Items:       true,
+		Operators:   operators,
+	}
+}
+
+// gauntletAilments runs from 1 to num, making every fourth one tough, every
+// sixth cursed and 7 spreading
+func gauntletAilments(num int) []models.Ailment {
+	ailments := make([]models.Ailment, num)
+	for i := range ailments {
+		value := i + 1
+		ailments[i] = models.Ailment{Value: value}
+
+		switch {
+		case value == 7:
+			ailments[i].Kind = models.AILMENT_SPREADING
+		case value%6 == 0:
+			ailments[i].Kind = models.AILMENT_CURSED
+		case value%4 == 0:
+			ailments[i].Kind = models.AILMENT_TOUGH
+		}
+	}
+	return ailments
+}
+
+// Clearing a level gives back this many lives, up to MAX_LIVES
+const LEVEL_BONUS_LIVES = 1
+
+// Campaign plays the levels in order with one seeded run of dice. Lives and
+// items carry over from each level to the next.
+type Campaign struct {
+	Seed    uint64
+	Level   int // Index into Levels
+	Levels  []Level
+	Game    *Game
+	Cleared []LevelSummary
+	source  models.DiceSource
+}
+
+// LevelSummary is how a level went, for the run summary
+type LevelSummary struct {
+	Name      string `json:"name"`
+	Turns     int    `json:"turns"`
+	Hits      int    `json:"hits"`
+	LivesLost int    `json:"livesLost"`
+	ItemsUsed int    `json:"itemsUsed"`
+}
+
+type RunSummary struct {
+	Seed          uint64         `json:"seed"`
+	Levels        []LevelSummary `json:"levels"`
+	LevelsCleared int            `json:"levelsCleared"`
+	Turns         int            `json:"turns"`
+	Hits          int            `json:"hits"`
+	LivesLost     int            `json:"livesLost"`
+	ItemsUsed     int            `json:"itemsUsed"`
+	Score         int            `json:"score"`
+	Won           bool           `json:"won"`
+}
+
+var ErrLevelNotCleared = errors.New("the level isn't cleared yet")
+
+// CreateCampaign starts a run on the first level. A seed of 0 picks one at
+// random, so every run can be replayed.
+func CreateCampaign(seed uint64) *Campaign {
+	for seed == 0 {
+		seed = mathrand.Uint64()
+	}
+	return RestoreCampaign(seed, 0, nil)
+}
+
+// RestoreCampaign starts a run partway through, at level with the levels
+// before it already cleared
+func RestoreCampaign(seed uint64, level int, cleared []LevelSummary) *Campaign {
+	level = max(min(level, len(CampaignLevels)-1), 0)
+	source := models.CreateSeededSource(seed)
+
+	return &Campaign{
+		Seed:    seed,
+		Level:   level,
+		Levels:  CampaignLevels,
+		Game:    CreateGameWithRules(CampaignLevels[level].Rules, source),
+		Cleared: cleared,
+		source:  source,
+	}
+}
+
+func (c *Campaign) CurrentLevel() Level {
+	return c.Levels[c.Level]
+}
+
+func (c *Campaign) IsLastLevel() bool {
+	return c.Level == len(c.Levels)-1
+}
+
+// LevelCleared reports whether the current level has been won
+func (c *Campaign) LevelCleared() bool {
+	return c.Game.IsOver() && c.Game.Won()
+}
+
+// Won reports whether every level has been cleared
+func (c *Campaign) Won() bool {
+	return c.IsLastLevel() && c.LevelCleared()
+}
+
+// IsOver reports whether the run has ended, won or lost
+func (c *Campaign) IsOver() bool {
+	return c.Won() || (c.Game.IsOver() && !c.Game.Won())
+}
+
+// NextLevel moves on from a cleared level, bringing the player's lives,
+// items and score along
+func (c *Campaign) NextLevel() error {
+	if !c.LevelCleared() {
+		return ErrLevelNotCleared
+	}
+	if c.IsLastLevel() {
+		return ErrGameOver
+	}
+
+	c.Cleared = append(c.Cleared, c.summarizeLevel())
+	player := c.Game.Player
+
+	c.Level++
+	c.Game = CreateGameWithRules(c.CurrentLevel().Rules, c.source)
+	c.Game.Player.Lives = min(player.Lives+LEVEL_BONUS_LIVES, MAX_LIVES)
+	c.Game.Player.Items = player.Items
+	c.Game.Player.Score = player.Score
+	logger.Logger().Info("next level", "level", c.Level+1, "name", c.CurrentLevel().Name, "lives", c.Game.Player.Lives, "score", player.Score)
+	return nil
+}
+
+// summarizeLevel totals up the turns played on the current level
+func (c *Campaign) summarizeLevel() LevelSummary {
+	summary := LevelSummary{Name: c.CurrentLevel().Name, Turns: len(c.Game.History)}
+	for _, turn := range c.Game.History {
+		if turn.HitAilment {
+			summary.Hits++
+		}
+		summary.LivesLost += turn.LivesLost
+		summary.ItemsUsed += len(turn.ItemsUsed)
+	}
+	return summary
+}
+
+// Summary totals up the run so far, including the level being played
+func (c *Campaign) Summary() RunSummary {
+	summary := RunSummary{
+		Seed:          c.Seed,
+		Levels:        append(append([]LevelSummary{}, c.Cleared...), c.summarizeLevel()),
+		LevelsCleared: len(c.Cleared),
+		Score:         c.Game.Player.Score,
+		Won:           c.Won(),
+	}
+	if c.LevelCleared() {
+		summary.LevelsCleared++
+	}
+
+	for _, level := range summary.Levels {
+		summary.Turns += level.Turns
+		summary.Hits += level.Hits
+		summary.LivesLost += level.LivesLost
+		summary.ItemsUsed += level.ItemsUsed
+	}
+	return summary
+}
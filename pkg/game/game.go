@@ -0,0 +1,365 @@
+package game
+
+import (
+	"dicer/pkg/logger"
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/*************************************
+* Game
+*************************************/
+// Game holds the rules of play shared by every frontend. Each action checks
+// it's allowed in the current phase before changing anything.
+type Game struct {
+	Rules   Rules             `json:"rules"`
+	Round   int               `json:"round"`
+	Player  models.Player     `json:"player"`
+	Turn    *models.Turn      `json:"turn"`
+	Source  models.DiceSource `json:"-"` // Saved games roll from a new source
+	History []TurnRecord      `json:"history,omitempty"`
+}
+
+// TurnRecord is a scored turn, kept so finished turns can be reviewed
+type TurnRecord struct {
+	Round          int    `json:"round"`
+	Dice           []int  `json:"dice"`
+	Expression     string `json:"expression,omitempty"` // Challenged turns have no expression or result
+	Result         *int   `json:"result,omitempty"`
+	HitAilment     bool   `json:"hitAilment"`
+	RemovedAilment bool   `json:"removedAilment"`
+	Cursed         bool   `json:"cursed"`
+	LostLife       bool   `json:"lostLife"`
+	LivesLost      int    `json:"livesLost,omitempty"`
+	PolicyNote     string `json:"policyNote,omitempty"`
+	Challenged     bool   `json:"challenged,omitempty"`
+	Reveal         string `json:"reveal,omitempty"`
+	Shielded       bool   `json:"shielded,omitempty"`
+	Score          int    `json:"score"`
+
+	Parts []models.PartResult `json:"parts,omitempty"`
+
+	ItemsUsed  []models.ItemKind `json:"itemsUsed,omitempty"`
+	EarnedItem models.ItemKind   `json:"earnedItem,omitempty"`
+}
+
+// Every ITEM_STREAK turns in a row that remove an ailment earn an item
+const ITEM_STREAK = 3
+
+var ErrGameOver = errors.New("the game is over")
+
+// PhaseError is returned for an action the current phase doesn't allow
+type PhaseError struct {
+	Phase models.TurnPhase
+}
+
+func (e *PhaseError) Error() string {
+	return fmt.Sprintf("can't do that while waiting to %s", e.Phase.Action())
+}
+
+func CreateGame(source models.DiceSource) *Game {
+	return CreateGameWithRules(DefaultRules(), source)
+}
+
+func CreateGameWithRules(rules Rules, source models.DiceSource) *Game {
+	logger.Logger().Info("new game", "dice", rules.NumDice, "sides", rules.DiceSides, "lives", rules.MaxLives, "items", rules.Items, "policy", rules.ResultPolicy().Name())
+	return &Game{
+		Rules:  rules,
+		Round:  1,
+		Player: models.CreatePlayerWith(rules.MaxLives, rules.CreateAilments()),
+		Turn:   models.CreateTurn(1, source),
+		Source: source,
+	}
+}
+
+func (g *Game) Phase() (models.TurnPhase, error) {
+	return g.Turn.Stack.Top()
+}
+
+func (g *Game) IsOver() bool {
+	return !g.Player.Ailments.HasAilments() || !g.Player.HasLives()
+}
+
+func (g *Game) Won() bool {
+	return !g.Player.Ailments.HasAilments()
+}
+
+// expectPhase returns an error naming what the game is waiting for if it
+// isn't in phase
+func (g *Game) expectPhase(phase models.TurnPhase) error {
+	current, err := g.Phase()
+	if err != nil {
+		return err
+	}
+	if current == models.GS_GameOver {
+		return ErrGameOver
+	}
+	if current != phase {
+		return &PhaseError{current}
+	}
+	return nil
+}
+
+// Roll starts the turn by rolling every die
+func (g *Game) Roll() error {
+	if err := g.expectPhase(models.GS_TurnStart); err != nil {
+		return err
+	}
+
+	g.Turn.Stack.Pop()
+	g.Turn.RollDice(g.Rules.NumDice, g.Rules.DiceSides)
+	logger.Logger().Info("rolled", "round", g.Round, "dice", g.diceValues())
+	return nil
+}
+
+func (g *Game) diceValues() []int {
+	values := make([]int, len(g.Turn.Dice))
+	for i, die := range g.Turn.Dice {
+		values[i] = die.Value
+	}
+	return values
+}
+
+// Reroll rolls the dice at the selected positions again. Selecting none keeps
+// every die.
+func (g *Game) Reroll(selected map[int]struct{}) error {
+	if err := g.expectPhase(models.GS_RollPhase); err != nil {
+		return err
+	}
+
+	for i := range selected {
+		if i < 0 || i >= len(g.Turn.Dice) {
+			return fmt.Errorf("there is no die %d", i+1)
+		}
+	}
+
+	g.Turn.RollSelectedDice(selected)
+	g.Turn.Stack.Pop()
+	logger.Logger().Info("rerolled", "round", g.Round, "rerolled", len(selected), "dice", g.diceValues())
+	return nil
+}
+
+// UseItem uses the item at index in the player's inventory. Items can only
+// be used before the dice are settled.
+func (g *Game) UseItem(index int, use models.ItemUse) error {
+	if !g.Rules.Items {
+		return errors.New("items aren't part of these rules")
+	}
+	if err := g.expectPhase(models.GS_RollPhase); err != nil {
+		return err
+	}
+	if index < 0 || index >= len(g.Player.Items) {
+		return fmt.Errorf("there is no item %d", index+1)
+	}
+
+	kind := g.Player.Items[index]
+	effect, ok := models.LookupItem(kind)
+	if !ok {
+		return fmt.Errorf("unknown item %q", kind)
+	}
+	if err := effect.Apply(g.Turn, use); err != nil {
+		return err
+	}
+
+	g.Player.TakeItem(index)
+	g.Turn.ItemsUsed = append(g.Turn.ItemsUsed, kind)
+	logger.Logger().Info("used item", "round", g.Round, "item", kind, "die", use.Die+1, "value", use.Value, "dice", g.diceValues())
+	return nil
+}
+
+// awardItem hands out an item for clearing anything tougher than a normal
+// ailment, or for a streak of clears. The dice source picks which, so seeded
+// games stay repeatable.
+func (g *Game) awardItem() {
+	cleared := g.Turn.Cleared
+	if len(cleared) == 0 {
+		return
+	}
+
+	// A combo can clear several at once, stepping over a multiple of the
+	// streak. A turn that also missed ended the streak, leaving it at 0.
+	streak := g.Player.Streak
+	tough := slices.ContainsFunc(cleared, func(kind models.AilmentKind) bool { return kind != models.AILMENT_NORMAL })
+	if !tough && (streak == 0 || streak/ITEM_STREAK == (streak-len(cleared))/ITEM_STREAK) {
+		return
+	}
+
+	kind := models.Items[g.Source.Roll(len(models.Items))-1].Kind()
+	if g.Player.AddItem(kind) {
+		g.Turn.EarnedItem = kind
+		logger.Logger().Info("earned item", "round", g.Round, "item", kind, "streak", g.Player.Streak)
+	}
+}
+
+// Submit scores exp against the dice. An invalid expression leaves the game
+// waiting for another go and returns an *ExpressionError.
+func (g *Game) Submit(exp string) error {
+	if err := g.expectPhase(models.GS_ExpressionPhase); err != nil {
+		return err
+	}
+
+	if err := ValidateCombo(exp, g.Turn.Dice); err != nil {
+		logger.Logger().Debug("invalid expression", "expression", exp, "pos", err.Pos, "error", err.Message)
+		return err
+	}
+	if err := ValidateOperators(exp, g.Rules.AllowedOperators()); err != nil {
+		logger.Logger().Debug("invalid expression", "expression", exp, "pos", err.Pos, "error", err.Message)
+		return err
+	}
+
+	g.Turn.Expression = exp
+	previous := g.previousSolutions()
+	g.Turn.Parts = nil
+	for _, part := range SplitCombo(exp) {
+		trimmed := strings.TrimSpace(part.Expression)
+		canonical, err := math.Canonicalize(trimmed)
+		if err != nil {
+			canonical = trimmed
+		}
+
+		_, repeat := previous[canonical]
+		g.Turn.Parts = append(g.Turn.Parts, models.PartResult{
+			Expression: trimmed,
+			Canonical:  canonical,
+			Result:     math.EvaluateExpression(part.Expression),
+			Repeat:     repeat,
+		})
+	}
+	g.Turn.Result = g.Turn.Parts[0].Result
+	g.Turn.ApplyResult(&g.Player, g.Rules.ResultPolicy())
+	g.Turn.Stack.Pop()
+	if g.Rules.Items {
+		g.awardItem()
+	}
+
+	results := make([]int, len(g.Turn.Parts))
+	for i, part := range g.Turn.Parts {
+		results[i] = part.Result
+	}
+	logger.Logger().Info("submitted",
+		"round", g.Round,
+		"expression", exp,
+		"results", results,
+		"hit", g.Turn.HitAilment,
+		"removed", g.Turn.RemovedAilment,
+		"livesLost", g.Turn.LivesLost,
+		"score", g.Turn.Score,
+		"lives", g.Player.Lives,
+	)
+
+	g.recordTurn()
+	return nil
+}
+
+// previousSolutions are the canonical forms of last turn's parts that hit
+func (g *Game) previousSolutions() map[string]struct{} {
+	solutions := make(map[string]struct{})
+	if len(g.History) == 0 {
+		return solutions
+	}
+	for _, part := range g.History[len(g.History)-1].Parts {
+		if part.Hit {
+			solutions[part.Canonical] = struct{}{}
+		}
+	}
+	return solutions
+}
+
+// Challenge claims that the dice can't reach any remaining ailment. The
+// solver looks for an expression to check the claim. A right claim skips the
+// turn, and a wrong one reveals an expression that would have hit and costs
+// what the result policy says.
+func (g *Game) Challenge() error {
+	search, err := g.StartChallenge()
+	if err != nil {
+		return err
+	}
+	return g.ResolveChallenge(search())
+}
+
+// StartChallenge checks a challenge can be made now and returns the search
+// for an expression that would have hit. The search works on copies of the
+// dice and ailments, so it can run while a frontend carries on drawing. Its
+// result goes to ResolveChallenge.
+func (g *Game) StartChallenge() (func() string, error) {
+	if err := g.expectPhase(models.GS_ExpressionPhase); err != nil {
+		return nil, err
+	}
+	if len(g.Turn.Dice) > math.MAX_SOLVER_DICE {
+		return nil, fmt.Errorf("a challenge can only be checked with up to %d dice", math.MAX_SOLVER_DICE)
+	}
+
+	values, ailments, operators := g.diceValues(), g.Player.Ailments.Values(), g.Rules.AllowedOperators()
+	return func() string {
+		_, reveal, _ := math.ReachAny(values, ailments, operators)
+		return reveal
+	}, nil
+}
+
+// ResolveChallenge settles a challenge with the expression its search
+// revealed, or upholds it when reveal is empty
+func (g *Game) ResolveChallenge(reveal string) error {
+	if err := g.expectPhase(models.GS_ExpressionPhase); err != nil {
+		return err
+	}
+
+	g.Turn.ApplyChallenge(&g.Player, reveal, g.Rules.ResultPolicy().ChallengeCost())
+	g.Turn.Stack.Pop()
+	logger.Logger().Info("challenged", "round", g.Round, "upheld", reveal == "", "reveal", reveal, "lives", g.Player.Lives)
+
+	g.recordTurn()
+	return nil
+}
+
+// recordTurn adds the scored turn to the history
+func (g *Game) recordTurn() {
+	record := TurnRecord{
+		Round:          g.Round,
+		Score:          g.Turn.Score,
+		HitAilment:     g.Turn.HitAilment,
+		RemovedAilment: g.Turn.RemovedAilment,
+		Cursed:         g.Turn.Cursed,
+		LostLife:       g.Turn.LostLife,
+		LivesLost:      g.Turn.LivesLost,
+		PolicyNote:     g.Turn.PolicyNote,
+		Challenged:     g.Turn.Challenged,
+		Reveal:         g.Turn.Reveal,
+		Shielded:       g.Turn.Shielded,
+		ItemsUsed:      g.Turn.ItemsUsed,
+		EarnedItem:     g.Turn.EarnedItem,
+		Dice:           g.diceValues(),
+	}
+	if !g.Turn.Challenged {
+		result := g.Turn.Result
+		record.Expression = g.Turn.Expression
+		record.Result = &result
+		record.Parts = g.Turn.Parts
+	}
+	g.History = append(g.History, record)
+}
+
+// NextTurn moves on from the results, ending the game if it's been won or lost
+func (g *Game) NextTurn() error {
+	if err := g.expectPhase(models.GS_ResultsPhase); err != nil {
+		return err
+	}
+
+	if g.IsOver() {
+		g.Turn.Stack.Push(models.GS_GameOver)
+		logger.Logger().Info("game over", "won", g.Won(), "rounds", g.Round, "score", g.Player.Score, "lives", g.Player.Lives)
+		return nil
+	}
+
+	spread := g.Player.Ailments.Spread()
+	if len(spread) > 0 {
+		logger.Logger().Info("ailments spread", "round", g.Round, "to", spread)
+	}
+	g.Round++
+	g.Turn = models.CreateTurn(g.Round, g.Source)
+	g.Turn.Spread = spread
+	return nil
+}
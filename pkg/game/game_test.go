@@ -0,0 +1,104 @@
+package game
+
+import (
+	"dicer/pkg/models"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAwardItem(t *testing.T) {
+	normal, tough := models.AILMENT_NORMAL, models.AILMENT_TOUGH
+	tests := []struct {
+		name    string
+		streak  int // After the turn
+		cleared []models.AilmentKind
+		want    bool
+	}{
+		{name: "nothing cleared", streak: 0},
+		{name: "short of the streak", streak: ITEM_STREAK - 1, cleared: []models.AilmentKind{normal}},
+		{name: "reaching the streak", streak: ITEM_STREAK, cleared: []models.AilmentKind{normal}, want: true},
+		{name: "combo stepping over the streak", streak: ITEM_STREAK + 1, cleared: []models.AilmentKind{normal, normal}, want: true},
+		{name: "combo short of the next streak", streak: ITEM_STREAK + 2, cleared: []models.AilmentKind{normal}},
+		{name: "combo that also missed", streak: 0, cleared: []models.AilmentKind{normal, normal}},
+		{name: "tough clear in a combo that missed", streak: 0, cleared: []models.AilmentKind{tough}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.Items = true
+			g := CreateGameWithRules(rules, models.CreateScriptedSource(1))
+			g.Player.Streak = tt.streak
+			g.Turn.Cleared = tt.cleared
+
+			g.awardItem()
+			if earned := g.Turn.EarnedItem != ""; earned != tt.want {
+				t.Errorf("earned %q, want an item: %v", g.Turn.EarnedItem, tt.want)
+			}
+		})
+	}
+}
+
+// rolledGame is a game waiting for an expression for the dice 3 4 6 2
+func rolledGame(t *testing.T) *Game {
+	t.Helper()
+	g := CreateGame(models.CreateScriptedSource(3, 4, 6, 2))
+	if err := g.Roll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Reroll(nil); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestChallengeAfterInvalidSubmit(t *testing.T) {
+	g := rolledGame(t)
+	if err := g.Submit("1 + 2"); err == nil {
+		t.Fatal("Submit(1 + 2) was accepted with the dice 3 4 6 2")
+	}
+	if g.Turn.Expression != "" {
+		t.Fatalf("the rejected expression was kept as %q", g.Turn.Expression)
+	}
+	if err := g.Challenge(); err != nil {
+		t.Fatal(err)
+	}
+
+	state := g.State()
+	if !state.Challenged || state.Reveal == "" {
+		t.Fatalf("state is %+v, want a failed challenge", state)
+	}
+	if state.Expression != "" || state.Result != nil || state.Parts != nil {
+		t.Errorf("challenged state has expression %q, result %v and parts %v, want none", state.Expression, state.Result, state.Parts)
+	}
+
+	record := g.History[len(g.History)-1]
+	if record.Expression != "" || record.Result != nil || record.Parts != nil {
+		t.Errorf("challenged record has expression %q, result %v and parts %v, want none", record.Expression, record.Result, record.Parts)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"expression"`, `"result"`, `"parts"`} {
+		if strings.Contains(string(data), key) {
+			t.Errorf("challenged record has %s: %s", key, data)
+		}
+	}
+}
+
+func TestSubmitAfterInvalidSubmit(t *testing.T) {
+	g := rolledGame(t)
+	if err := g.Submit("1 + 2"); err == nil {
+		t.Fatal("Submit(1 + 2) was accepted with the dice 3 4 6 2")
+	}
+	if err := g.Submit("3 + 4 - 6 / 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	record := g.History[len(g.History)-1]
+	if record.Expression != "3 + 4 - 6 / 2" || record.Result == nil || *record.Result != 4 {
+		t.Errorf("record has expression %q and result %v, want 3 + 4 - 6 / 2 making 4", record.Expression, record.Result)
+	}
+}
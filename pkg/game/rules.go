@@ -0,0 +1,181 @@
+package game
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*************************************
+* Rules
+*************************************/
+// Rules are the numbers a game is played with. Ailments, when set, replace
+// the plain ailments 1 to NumAilments. Items turns on earning and using items.
+// Operators limits the operators expressions can use, or allows all of them
+// when empty. Policy names the models.ResultPolicy that prices misses.
+type Rules struct {
+	NumDice     int              `json:"numDice"`
+	DiceSides   int              `json:"diceSides"`
+	NumAilments int              `json:"numAilments"`
+	MaxLives    int              `json:"maxLives"`
+	Ailments    []models.Ailment `json:"ailments,omitempty"`
+	Items       bool             `json:"items,omitempty"`
+	Operators   string           `json:"operators,omitempty"`
+	Policy      string           `json:"policy,omitempty"`
+}
+
+// Limits keep custom rules playable and cheap to check. Every character of
+// an expression is separated by a space, so a die can't go above 9. Dice stop
+// one short of what the solver takes, leaving room for an Extra Die.
+const (
+	MAX_DICE     = math.MAX_SOLVER_DICE - 1
+	MAX_SIDES    = 9
+	MAX_AILMENTS = 50
+	MAX_LIVES    = 20
+	MAX_HITS     = 5
+)
+
+const RULES_CLASSIC = "classic"
+
+// RuleSets can be picked by name
+var RuleSets = map[string]Rules{
+	RULES_CLASSIC: DefaultRules(),
+	"items": {
+		NumDice:     config.NumDice,
+		DiceSides:   config.DiceSides,
+		NumAilments: config.NumAilments,
+		MaxLives:    config.MaxLives,
+		Items:       true,
+	},
+	"variants": {
+		NumDice:   config.NumDice,
+		DiceSides: config.DiceSides,
+		MaxLives:  config.MaxLives,
+		Items:     true,
+		Ailments: []models.Ailment{
+			{Value: 1},
+			{Value: 3, Kind: models.AILMENT_TOUGH},
+			{Value: 5, Kind: models.AILMENT_SPREADING},
+			{Value: 8},
+			{Value: 12, Kind: models.AILMENT_TOUGH},
+			{Value: 24, Kind: models.AILMENT_CURSED},
+		},
+	},
+	models.POLICY_NEAR_MISS: withPolicy(models.POLICY_NEAR_MISS),
+	models.POLICY_PASS:      withPolicy(models.POLICY_PASS),
+	models.POLICY_BUST:      withPolicy(models.POLICY_BUST),
+	"dozens": {
+		NumDice:   config.NumDice,
+		DiceSides: config.DiceSides,
+		MaxLives:  config.MaxLives,
+		Ailments:  []models.Ailment{{Value: 12}, {Value: 24}, {Value: 36}},
+	},
+}
+
+// withPolicy is the classic rules with a different result policy
+func withPolicy(policy string) Rules {
+	rules := DefaultRules()
+	rules.Policy = policy
+	return rules
+}
+
+func RuleSetNames() []string {
+	names := make([]string, 0, len(RuleSets))
+	for name := range RuleSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupRules finds the rule set called name
+func LookupRules(name string) (Rules, error) {
+	rules, ok := RuleSets[name]
+	if !ok {
+		return Rules{}, fmt.Errorf("unknown rule set %q, expected one of: %s", name, strings.Join(RuleSetNames(), ", "))
+	}
+	return rules, nil
+}
+
+// CreateAilments makes the ailments a game with these rules starts with
+func (r Rules) CreateAilments() *models.Ailments {
+	if len(r.Ailments) > 0 {
+		return models.CreateAilmentsFrom(r.Ailments)
+	}
+	return models.CreateAilments(r.NumAilments)
+}
+
+// AllowedOperators are the operators expressions can use under these rules
+func (r Rules) AllowedOperators() string {
+	if r.Operators == "" {
+		return math.ALL_OPERATORS
+	}
+	return r.Operators
+}
+
+// ResultPolicy is the policy named by Policy, or the standard one
+func (r Rules) ResultPolicy() models.ResultPolicy {
+	if policy, ok := models.LookupPolicy(r.Policy); ok {
+		return policy
+	}
+	return models.StandardPolicy{}
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		NumDice:     config.NumDice,
+		DiceSides:   config.DiceSides,
+		NumAilments: config.NumAilments,
+		MaxLives:    config.MaxLives,
+	}
+}
+
+func (r Rules) Validate() error {
+	switch {
+	case r.NumDice < 1 || r.NumDice > MAX_DICE:
+		return fmt.Errorf("numDice must be between 1 and %d", MAX_DICE)
+	case r.DiceSides < 2 || r.DiceSides > MAX_SIDES:
+		return fmt.Errorf("diceSides must be between 2 and %d", MAX_SIDES)
+	case len(r.Ailments) == 0 && (r.NumAilments < 1 || r.NumAilments > MAX_AILMENTS):
+		return fmt.Errorf("numAilments must be between 1 and %d", MAX_AILMENTS)
+	case len(r.Ailments) > MAX_AILMENTS:
+		return fmt.Errorf("there can be at most %d ailments", MAX_AILMENTS)
+	case r.MaxLives < 1 || r.MaxLives > MAX_LIVES:
+		return fmt.Errorf("maxLives must be between 1 and %d", MAX_LIVES)
+	}
+
+	if _, ok := models.LookupPolicy(r.Policy); !ok {
+		return fmt.Errorf("unknown policy %q, expected one of: %s", r.Policy, strings.Join(models.PolicyNames(), ", "))
+	}
+
+	for _, op := range r.Operators {
+		if !strings.ContainsRune(math.ALL_OPERATORS, op) {
+			return fmt.Errorf("operators can only include %s", math.ALL_OPERATORS)
+		}
+	}
+
+	seen := make(map[int]struct{})
+	for _, a := range r.Ailments {
+		if _, duplicate := seen[a.Value]; duplicate {
+			return fmt.Errorf("ailment %d appears more than once", a.Value)
+		}
+		seen[a.Value] = struct{}{}
+
+		switch {
+		case a.Value < 1:
+			return fmt.Errorf("ailment %d must be at least 1", a.Value)
+		case a.Hits > MAX_HITS:
+			return fmt.Errorf("ailment %d can need at most %d hits", a.Value, MAX_HITS)
+		}
+
+		switch a.Kind {
+		case "", models.AILMENT_NORMAL, models.AILMENT_TOUGH, models.AILMENT_CURSED, models.AILMENT_SPREADING:
+		default:
+			return fmt.Errorf("ailment %d has unknown kind %q", a.Value, a.Kind)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"dicer/pkg/models"
+	"slices"
+)
+
+/*************************************
+* State
+*************************************/
+// State is a snapshot of a game for frontends that talk JSON
+type State struct {
+	Round          int                 `json:"round"`
+	Phase          string              `json:"phase"`
+	Next           string              `json:"next"`
+	Lives          int                 `json:"lives"`
+	Score          int                 `json:"score"`
+	Policy         string              `json:"policy"`
+	Ailments       []int               `json:"ailments"`
+	AilmentDetails []models.Ailment    `json:"ailmentDetails"`
+	Dice           []int               `json:"dice"`
+	Expression     string              `json:"expression,omitempty"`
+	Result         *int                `json:"result,omitempty"`
+	Parts          []models.PartResult `json:"parts,omitempty"`
+	TurnScore      int                 `json:"turnScore,omitempty"`
+	HitAilment     bool                `json:"hitAilment,omitempty"`
+	RemovedAilment bool                `json:"removedAilment,omitempty"`
+	Cursed         bool                `json:"cursed,omitempty"`
+	LostLife       bool                `json:"lostLife,omitempty"`
+	LivesLost      int                 `json:"livesLost,omitempty"`
+	PolicyNote     string              `json:"policyNote,omitempty"`
+	Challenged     bool                `json:"challenged,omitempty"`
+	Reveal         string              `json:"reveal,omitempty"`
+	Spread         []int               `json:"spread,omitempty"`
+	Won            bool                `json:"won,omitempty"`
+
+	Items      []models.ItemKind `json:"items,omitempty"`
+	ItemsUsed  []models.ItemKind `json:"itemsUsed,omitempty"`
+	Shield     bool              `json:"shield,omitempty"`
+	Shielded   bool              `json:"shielded,omitempty"`
+	EarnedItem models.ItemKind   `json:"earnedItem,omitempty"`
+}
+
+func (g *Game) State() State {
+	phase, _ := g.Phase()
+
+	// Ailments lists only those still in play, the details include the rest
+	state := State{
+		Round:          g.Round,
+		Phase:          phase.String(),
+		Next:           phase.Action(),
+		Lives:          g.Player.Lives,
+		Score:          g.Player.Score,
+		Policy:         g.Rules.ResultPolicy().Name(),
+		Ailments:       append([]int{}, g.Player.Ailments.Values()...),
+		AilmentDetails: slices.Clone(g.Player.Ailments.List),
+		Dice:           []int{},
+		Spread:         g.Turn.Spread,
+		Items:          slices.Clone(g.Player.Items),
+		ItemsUsed:      g.Turn.ItemsUsed,
+		Shield:         g.Turn.Shield,
+	}
+
+	for _, die := range g.Turn.Dice {
+		state.Dice = append(state.Dice, die.Value)
+	}
+
+	if phase == models.GS_ResultsPhase || phase == models.GS_GameOver {
+		// A challenged turn submitted nothing
+		if !g.Turn.Challenged {
+			result := g.Turn.Result
+			state.Expression = g.Turn.Expression
+			state.Result = &result
+			state.Parts = g.Turn.Parts
+		}
+		state.TurnScore = g.Turn.Score
+		state.HitAilment = g.Turn.HitAilment
+		state.RemovedAilment = g.Turn.RemovedAilment
+		state.Cursed = g.Turn.Cursed
+		state.LostLife = g.Turn.LostLife
+		state.LivesLost = g.Turn.LivesLost
+		state.PolicyNote = g.Turn.PolicyNote
+		state.Challenged = g.Turn.Challenged
+		state.Reveal = g.Turn.Reveal
+		state.Shielded = g.Turn.Shielded
+		state.EarnedItem = g.Turn.EarnedItem
+		state.Won = g.Won()
+	}
+
+	return state
+}
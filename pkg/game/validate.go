@@ -0,0 +1,280 @@
+package game
+
+import (
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"dicer/pkg/single"
+	"dicer/pkg/stack"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*************************************
+* Expression Validation
+*************************************/
+// ExpressionError reports what's wrong with an expression and the byte
+// position it was found at
+type ExpressionError struct {
+	Pos     int
+	Message string
+}
+
+func (e *ExpressionError) Error() string {
+	return e.Message
+}
+
+// ValidateOperators reports the first operator in exp that isn't one of
+// operators
+func ValidateOperators(exp string, operators string) *ExpressionError {
+	for index, runeValue := range exp {
+		if math.IsOperator(string(runeValue)) && !strings.ContainsRune(operators, runeValue) {
+			return &ExpressionError{index, fmt.Sprintf("%c isn't allowed by these rules", runeValue)}
+		}
+	}
+	return nil
+}
+
+type expressionToken struct {
+	pos   int
+	value string
+}
+
+// ValidateExpression checks exp against the rolled dice and reports the first
+// problem along with the byte position it was found at.
+func ValidateExpression(exp string, dice []models.Dice) *ExpressionError {
+	if strings.TrimSpace(exp) == "" {
+		return &ExpressionError{0, "Enter an expression using every die"}
+	}
+
+	if err := validateCharacters(exp, false); err != nil {
+		return err
+	}
+	if err := validateStructure(exp, 0); err != nil {
+		return err
+	}
+	if err := validateDiceUsage(tokenizeExpression(exp), dice, len(exp), "Expression still needs to use %s"); err != nil {
+		return err
+	}
+	return validateEvaluation(exp, 0)
+}
+
+// ComboPart is one comma separated part of a combo, and the byte position it
+// starts at in the whole expression
+type ComboPart struct {
+	Pos        int
+	Expression string
+}
+
+// SplitCombo splits exp at its commas. An expression without any is a combo
+// of one part.
+func SplitCombo(exp string) []ComboPart {
+	var parts []ComboPart
+
+	start := 0
+	for index, runeValue := range exp {
+		if runeValue == ',' {
+			parts = append(parts, ComboPart{start, exp[start:index]})
+			start = index + 1
+		}
+	}
+	return append(parts, ComboPart{start, exp[start:]})
+}
+
+// ValidateCombo is ValidateExpression for a comma separated combo such as
+// "3 + 1 , 6 / 2". Each part must be a valid expression on its own, and
+// between them the parts must use every die exactly once.
+func ValidateCombo(exp string, dice []models.Dice) *ExpressionError {
+	parts := SplitCombo(exp)
+	if len(parts) == 1 {
+		return ValidateExpression(exp, dice)
+	}
+
+	if err := validateCharacters(exp, true); err != nil {
+		return err
+	}
+
+	var tokens []expressionToken
+	for _, part := range parts {
+		if strings.TrimSpace(part.Expression) == "" {
+			return &ExpressionError{part.Pos, "Every part of a combo needs an expression"}
+		}
+		if err := validateStructure(part.Expression, part.Pos); err != nil {
+			return err
+		}
+		for _, token := range tokenizeExpression(part.Expression) {
+			tokens = append(tokens, expressionToken{part.Pos + token.pos, token.value})
+		}
+	}
+
+	if err := validateDiceUsage(tokens, dice, len(exp), "Between them the parts still need to use %s"); err != nil {
+		return err
+	}
+
+	for _, part := range parts {
+		if err := validateEvaluation(part.Expression, part.Pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCharacters checks exp only has numbers, operators and parentheses,
+// and commas when combos are allowed, each separated by a space
+func validateCharacters(exp string, combo bool) *ExpressionError {
+	for index, runeValue := range exp {
+		if runeValue == ',' && combo {
+			continue
+		}
+		if runeValue != ' ' && !math.IsOperand(string(runeValue)) && !math.IsOperator(string(runeValue)) && runeValue != '(' && runeValue != ')' {
+			return &ExpressionError{index, fmt.Sprintf("'%c' isn't a number, operator or parenthesis", runeValue)}
+		}
+	}
+
+	if index := undelimitedIndex(exp); index != -1 {
+		return &ExpressionError{index, "Every character must be separated by a space"}
+	}
+	return nil
+}
+
+// validateStructure checks the parentheses and the order of the tokens in
+// exp, which starts at offset in the expression the player entered
+func validateStructure(exp string, offset int) *ExpressionError {
+	if index := math.UnbalancedParenIndex(exp); index != -1 {
+		if exp[index] == '(' {
+			return &ExpressionError{offset + index, "This ( is never closed"}
+		}
+		return &ExpressionError{offset + index, "This ) has no matching ("}
+	}
+
+	// Numbers and operators must alternate, with parentheses wrapping numbers
+	expectOperand := true
+	for _, token := range tokenizeExpression(exp) {
+		switch {
+		case expectOperand && token.value == "(":
+		case expectOperand && math.IsOperand(token.value):
+			expectOperand = false
+		case expectOperand:
+			return &ExpressionError{offset + token.pos, fmt.Sprintf("Expected a number or ( but found %s", token.value)}
+		case token.value == ")":
+		case math.IsOperator(token.value):
+			expectOperand = true
+		default:
+			return &ExpressionError{offset + token.pos, fmt.Sprintf("Expected an operator or ) but found %s", token.value)}
+		}
+	}
+	if expectOperand {
+		return &ExpressionError{offset + len(exp), "Expression can't end with an operator"}
+	}
+	return nil
+}
+
+// validateDiceUsage checks the numbers in tokens are exactly the dice. It
+// points at the first number that wasn't rolled or is used too often, and
+// otherwise fills unused in with the dice left over, reported at end.
+func validateDiceUsage(tokens []expressionToken, dice []models.Dice, end int, unused string) *ExpressionError {
+	rolled := single.CreateMultiset[int]()
+	left := single.CreateMultiset[int]()
+	for _, die := range dice {
+		rolled.Add(die.Value)
+		left.Add(die.Value)
+	}
+
+	for _, token := range tokens {
+		if !math.IsOperand(token.value) {
+			continue
+		}
+		num, _ := strconv.Atoi(token.value)
+		if left.Remove(num) {
+			continue
+		}
+		if !rolled.Contains(num) {
+			return &ExpressionError{token.pos, fmt.Sprintf("%d isn't one of the dice", num)}
+		}
+		return &ExpressionError{token.pos, fmt.Sprintf("%d was only rolled %s", num, timesText(rolled.Count(num)))}
+	}
+
+	if !left.IsEmpty() {
+		missing := left.ToSlice()
+		slices.Sort(missing)
+		return &ExpressionError{end, fmt.Sprintf(unused, listText(missing))}
+	}
+	return nil
+}
+
+func timesText(count int) string {
+	switch count {
+	case 1:
+		return "once"
+	case 2:
+		return "twice"
+	}
+	return fmt.Sprintf("%d times", count)
+}
+
+// listText joins values as "1, 2 and 3"
+func listText(values []int) string {
+	words := make([]string, len(values))
+	for i, value := range values {
+		words[i] = strconv.Itoa(value)
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// validateEvaluation catches what only shows up when exp is worked out, such
+// as dividing by zero
+func validateEvaluation(exp string, offset int) *ExpressionError {
+	if _, err := math.EvaluatePostfixExpression(math.InfixToPostfix(exp)); err != nil {
+		return &ExpressionError{offset + len(exp), err.Error()}
+	}
+	return nil
+}
+
+// tokenizeExpression splits exp on spaces, remembering where each token starts
+func tokenizeExpression(exp string) []expressionToken {
+	var tokens []expressionToken
+
+	start := -1
+	for index, runeValue := range exp {
+		if runeValue == ' ' {
+			if start != -1 {
+				tokens = append(tokens, expressionToken{start, exp[start:index]})
+				start = -1
+			}
+			continue
+		}
+		if start == -1 {
+			start = index
+		}
+	}
+	if start != -1 {
+		tokens = append(tokens, expressionToken{start, exp[start:]})
+	}
+
+	return tokens
+}
+
+// undelimitedIndex returns the index of the first character that isn't
+// separated from the one before it by a space, or -1 if there is none.
+func undelimitedIndex(exp string) int {
+	stack := stack.StackList[rune]{}
+
+	for index, runeValue := range exp {
+		if index == 0 {
+			stack.Push(runeValue)
+			continue
+		}
+
+		lastRune, _ := stack.Top()
+		if runeValue != 32 && lastRune != 32 {
+			return index
+		}
+		stack.Push(runeValue)
+	}
+
+	return -1
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"dicer/pkg/models"
+	"reflect"
+	"testing"
+)
+
+func rolled(values ...int) []models.Dice {
+	dice := make([]models.Dice, len(values))
+	for i, value := range values {
+		dice[i] = models.Dice{Value: value, Sides: 6}
+	}
+	return dice
+}
+
+func TestSplitCombo(t *testing.T) {
+	tests := []struct {
+		exp  string
+		want []ComboPart
+	}{
+		{"1 + 2", []ComboPart{{0, "1 + 2"}}},
+		{"3 + 1 , 6 / 2", []ComboPart{{0, "3 + 1 "}, {7, " 6 / 2"}}},
+		{"1 , 2 , 3", []ComboPart{{0, "1 "}, {3, " 2 "}, {7, " 3"}}},
+		{",", []ComboPart{{0, ""}, {1, ""}}},
+		{"", []ComboPart{{0, ""}}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitCombo(tt.exp); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitCombo(%q) = %v, want %v", tt.exp, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCombo(t *testing.T) {
+	tests := []struct {
+		name    string
+		exp     string
+		dice    []models.Dice
+		wantPos int
+		wantMsg string // Empty when the combo is valid
+	}{
+		{name: "single expression", exp: "3 + 1 + 6 + 2", dice: rolled(3, 1, 6, 2)},
+		{name: "two parts", exp: "3 + 1 , 6 / 2", dice: rolled(3, 1, 6, 2)},
+		{name: "a part for every die", exp: "3 , 1 , 6 , 2", dice: rolled(3, 1, 6, 2)},
+		{name: "single expression missing a die", exp: "3 + 1 + 6", dice: rolled(3, 1, 6, 2), wantPos: 9, wantMsg: "Expression still needs to use 2"},
+		{name: "empty first part", exp: ", 3 + 1 , 6 / 2", dice: rolled(3, 1, 6, 2), wantPos: 0, wantMsg: "Every part of a combo needs an expression"},
+		{name: "empty last part", exp: "3 + 1 , 6 / 2 , ", dice: rolled(3, 1, 6, 2), wantPos: 15, wantMsg: "Every part of a combo needs an expression"},
+		{name: "comma not spaced", exp: "3 + 1, 6 / 2", dice: rolled(3, 1, 6, 2), wantPos: 5, wantMsg: "Every character must be separated by a space"},
+		{name: "unclosed paren in second part", exp: "3 + 1 , 6 / ( 2", dice: rolled(3, 1, 6, 2), wantPos: 12, wantMsg: "This ( is never closed"},
+		{name: "structure in second part", exp: "3 + 1 , 6 2", dice: rolled(3, 1, 6, 2), wantPos: 10, wantMsg: "Expected an operator or ) but found 2"},
+		{name: "die used in two parts", exp: "3 + 1 , 6 / 3", dice: rolled(3, 1, 6, 2), wantPos: 12, wantMsg: "3 was only rolled once"},
+		{name: "duplicate dice split between parts", exp: "3 + 1 , 3 * 2", dice: rolled(3, 1, 3, 2)},
+		{name: "not a die", exp: "3 + 1 , 6 / 2 - 4", dice: rolled(3, 1, 6, 2), wantPos: 16, wantMsg: "4 isn't one of the dice"},
+		{name: "dice left over", exp: "3 + 1 , 6", dice: rolled(3, 1, 6, 2), wantPos: 9, wantMsg: "Between them the parts still need to use 2"},
+		{name: "dividing by zero in first part", exp: "6 / ( 3 - 3 ) , 2", dice: rolled(3, 3, 6, 2), wantPos: 14, wantMsg: "Division by zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCombo(tt.exp, tt.dice)
+			switch {
+			case tt.wantMsg == "" && err != nil:
+				t.Fatalf("ValidateCombo(%q) = %q at %d, want no error", tt.exp, err.Message, err.Pos)
+			case tt.wantMsg == "":
+			case err == nil:
+				t.Fatalf("ValidateCombo(%q) = nil, want %q at %d", tt.exp, tt.wantMsg, tt.wantPos)
+			case err.Message != tt.wantMsg || err.Pos != tt.wantPos:
+				t.Fatalf("ValidateCombo(%q) = %q at %d, want %q at %d", tt.exp, err.Message, err.Pos, tt.wantMsg, tt.wantPos)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*************************************
+* Colored Handler
+*************************************/
+const (
+	ColorReset  = "\033[0m"
+	ColorRed    = "\033[31m"
+	ColorGreen  = "\033[32m"
+	ColorYellow = "\033[33m"
+	ColorBlue   = "\033[34m"
+	ColorPurple = "\033[35m"
+	ColorCyan   = "\033[36m"
+	ColorWhite  = "\033[37m"
+)
+
+// ColorHandler writes one colored line per record, for people reading logs in
+// a terminal
+type ColorHandler struct {
+	w      io.Writer
+	level  slog.Leveler
+	attrs  string // Fields added with WithAttrs, already formatted
+	prefix string // Group names to put before each key
+	mu     *sync.Mutex
+}
+
+func NewColorHandler(w io.Writer, level slog.Leveler) *ColorHandler {
+	return &ColorHandler{w: w, level: level, mu: &sync.Mutex{}}
+}
+
+func (h *ColorHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *ColorHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s[%s]%s %s%s", levelColor(r.Level), r.Level, ColorReset, r.Message, h.attrs)
+	r.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, h.prefix, attr)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *ColorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	for _, attr := range attrs {
+		writeAttr(&b, h.prefix, attr)
+	}
+
+	clone := *h
+	clone.attrs += b.String()
+	return &clone
+}
+
+func (h *ColorHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix += name + "."
+	return &clone
+}
+
+// writeAttr writes attr as " key=value", spreading groups out into dotted keys
+func writeAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			writeAttr(b, prefix, member)
+		}
+		return
+	}
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindString && strings.ContainsAny(value, " =\"") {
+		value = strconv.Quote(value)
+	}
+	fmt.Fprintf(b, " %s%s%s%s=%s", ColorBlue, prefix, attr.Key, ColorReset, value)
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return ColorRed
+	case level >= slog.LevelWarn:
+		return ColorYellow
+	case level >= slog.LevelInfo:
+		return ColorCyan
+	}
+	return ColorPurple
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestColorHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "message only",
+			log:  func(l *slog.Logger) { l.Info("rolled") },
+			want: ColorCyan + "[INFO]" + ColorReset + " rolled\n",
+		},
+		{
+			name: "level colors",
+			log:  func(l *slog.Logger) { l.Warn("w"); l.Error("e") },
+			want: ColorYellow + "[WARN]" + ColorReset + " w\n" + ColorRed + "[ERROR]" + ColorReset + " e\n",
+		},
+		{
+			name: "below the level",
+			log:  func(l *slog.Logger) { l.Debug("hidden") },
+		},
+		{
+			name: "fields",
+			log:  func(l *slog.Logger) { l.Info("submitted", "result", 4, "expression", "3 + 1") },
+			want: ColorCyan + "[INFO]" + ColorReset + " submitted " +
+				ColorBlue + "result" + ColorReset + "=4 " +
+				ColorBlue + "expression" + ColorReset + `="3 + 1"` + "\n",
+		},
+		{
+			name: "empty fields are dropped",
+			log:  func(l *slog.Logger) { l.Info("rolled", slog.Attr{}) },
+			want: ColorCyan + "[INFO]" + ColorReset + " rolled\n",
+		},
+		{
+			name: "group field",
+			log:  func(l *slog.Logger) { l.Info("rolled", slog.Group("dice", "count", 4)) },
+			want: ColorCyan + "[INFO]" + ColorReset + " rolled " + ColorBlue + "dice.count" + ColorReset + "=4\n",
+		},
+		{
+			name: "with attrs and group",
+			log:  func(l *slog.Logger) { l.With("round", 2).WithGroup("turn").Info("rolled", "dice", 4) },
+			want: ColorCyan + "[INFO]" + ColorReset + " rolled " +
+				ColorBlue + "round" + ColorReset + "=2 " +
+				ColorBlue + "turn.dice" + ColorReset + "=4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(slog.New(NewColorHandler(&buf, slog.LevelInfo)))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
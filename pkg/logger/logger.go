@@ -2,21 +2,94 @@ package logger
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
+/*************************************
+* Logger
+*************************************/
+// Logs are thrown away until Setup is called, so nothing can draw over the
+// TUI by accident
+var current = slog.New(slog.DiscardHandler)
+
+// ENV_LOG names a file to log to when --log-file isn't given
+const ENV_LOG = "DICER_LOG"
+
 const (
-	ColorReset  = "\033[0m"
-	ColorRed    = "\033[31m"
-	ColorGreen  = "\033[32m"
-	ColorYellow = "\033[33m"
-	ColorBlue   = "\033[34m"
-	ColorPurple = "\033[35m"
-	ColorCyan   = "\033[36m"
-	ColorWhite  = "\033[37m"
+	FORMAT_TEXT  = "text"
+	FORMAT_JSON  = "json"
+	FORMAT_COLOR = "color"
 )
 
-// formatArgs converts variadic arguments to a single string joined with " - "
+// Options choose where logs go and how they're written. File takes priority
+// over Writer, and with neither the logs are thrown away.
+type Options struct {
+	File   string
+	Writer io.Writer
+	Format string // One of the FORMAT_ constants, text when empty
+	Level  slog.Level
+}
+
+// Logger is where game events are logged
+func Logger() *slog.Logger {
+	return current
+}
+
+// Setup points the logger at a file or writer. The returned function closes
+// the file, if one was opened.
+func Setup(opts Options) (func() error, error) {
+	closeLog := func() error { return nil }
+
+	w := opts.Writer
+	if opts.File != "" {
+		path := os.ExpandEnv(opts.File)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return closeLog, err
+		}
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return closeLog, err
+		}
+		w, closeLog = file, file.Close
+	}
+	if w == nil {
+		current = slog.New(slog.DiscardHandler)
+		return closeLog, nil
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	switch opts.Format {
+	case FORMAT_TEXT, "":
+		current = slog.New(slog.NewTextHandler(w, handlerOpts))
+	case FORMAT_JSON:
+		current = slog.New(slog.NewJSONHandler(w, handlerOpts))
+	case FORMAT_COLOR:
+		current = slog.New(NewColorHandler(w, opts.Level))
+	default:
+		closeLog()
+		return func() error { return nil }, fmt.Errorf("unknown log format %q, expected one of: %s", opts.Format, strings.Join(Formats(), ", "))
+	}
+	return closeLog, nil
+}
+
+func Formats() []string {
+	return []string{FORMAT_TEXT, FORMAT_JSON, FORMAT_COLOR}
+}
+
+// ParseLevel reads a level such as "debug" or "warn"
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, fmt.Errorf("unknown log level %q, expected one of: debug, info, warn, error", name)
+	}
+	return level, nil
+}
+
+// formatArgs converts variadic arguments to a single string joined with " "
 func formatArgs(args ...interface{}) string {
 	if len(args) == 0 {
 		return ""
@@ -27,65 +100,25 @@ func formatArgs(args ...interface{}) string {
 		if i > 0 {
 			builder.WriteString(" ")
 		}
-
-		switch v := arg.(type) {
-		case string:
-			builder.WriteString(v)
-		case int:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case int8:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case int16:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case int32:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case int64:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case uint:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case uint8:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case uint16:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case uint32:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case uint64:
-			builder.WriteString(fmt.Sprintf("%d", v))
-		case float32:
-			builder.WriteString(fmt.Sprintf("%g", v))
-		case float64:
-			builder.WriteString(fmt.Sprintf("%g", v))
-		case bool:
-			builder.WriteString(fmt.Sprintf("%t", v))
-		default:
-			builder.WriteString(fmt.Sprintf("%v", v))
-		}
+		fmt.Fprint(&builder, arg)
 	}
 
 	return builder.String()
 }
 
-// Colored log helpers
+// Log helpers for messages that don't need any fields
 func LogInfo(args ...interface{}) {
-	msg := formatArgs(args...)
-	fmt.Printf("%s[INFO]%s %s\n", ColorCyan, ColorReset, msg)
+	current.Info(formatArgs(args...))
 }
 
 func LogSuccess(args ...interface{}) {
-	msg := formatArgs(args...)
-	fmt.Printf("%s[SUCCESS]%s %s\n", ColorGreen, ColorReset, msg)
+	current.Info(formatArgs(args...), "success", true)
 }
 
 func LogWarning(args ...interface{}) {
-	msg := formatArgs(args...)
-	fmt.Printf("%s[WARNING]%s %s\n", ColorYellow, ColorReset, msg)
+	current.Warn(formatArgs(args...))
 }
 
 func LogError(args ...interface{}) {
-	msg := formatArgs(args...)
-	fmt.Printf("%s[ERROR]%s %s\n", ColorRed, ColorReset, msg)
-}
-
-func LogBreak() {
-	fmt.Printf("%s--------------------------------%s\n", ColorPurple, ColorReset)
+	current.Error(formatArgs(args...))
 }
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setup calls Setup and puts the discarding logger back once the test is done
+func setup(t *testing.T, opts Options) (func() error, error) {
+	t.Helper()
+	t.Cleanup(func() { current = slog.New(slog.DiscardHandler) })
+	return Setup(opts)
+}
+
+func TestSetupFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   func(line string) bool
+	}{
+		{"", func(line string) bool { return strings.Contains(line, "level=INFO msg=rolled round=2") }},
+		{FORMAT_TEXT, func(line string) bool { return strings.Contains(line, "level=INFO msg=rolled round=2") }},
+		{FORMAT_JSON, func(line string) bool {
+			var record map[string]any
+			return json.Unmarshal([]byte(line), &record) == nil && record["msg"] == "rolled" && record["round"] == 2.0
+		}},
+		{FORMAT_COLOR, func(line string) bool { return strings.HasPrefix(line, ColorCyan+"[INFO]"+ColorReset+" rolled") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := setup(t, Options{Writer: &buf, Format: tt.format}); err != nil {
+				t.Fatal(err)
+			}
+			Logger().Info("rolled", "round", 2)
+			if line := buf.String(); !tt.want(line) {
+				t.Errorf("format %q wrote %q", tt.format, line)
+			}
+		})
+	}
+}
+
+func TestSetupLevel(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := setup(t, Options{Writer: &buf, Level: slog.LevelWarn}); err != nil {
+		t.Fatal(err)
+	}
+	LogInfo("quiet")
+	LogWarning("loud")
+	if got := buf.String(); strings.Contains(got, "quiet") || !strings.Contains(got, "loud") {
+		t.Errorf("logging at warn wrote %q, want only the warning", got)
+	}
+}
+
+func TestSetupUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	closeLog, err := setup(t, Options{Writer: &buf, Format: "yaml"})
+	if err == nil || !strings.Contains(err.Error(), `"yaml"`) {
+		t.Fatalf("Setup with format yaml returned %v, want an unknown format error", err)
+	}
+	if err := closeLog(); err != nil {
+		t.Errorf("closing after the error returned %v", err)
+	}
+}
+
+func TestSetupFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "debug.log")
+	for _, message := range []string{"first", "second"} {
+		closeLog, err := setup(t, Options{File: path, Format: FORMAT_JSON})
+		if err != nil {
+			t.Fatal(err)
+		}
+		LogInfo(message)
+		if err := closeLog(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each run appends to the file rather than replacing it
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "first") || !strings.Contains(lines[1], "second") {
+		t.Errorf("the log file holds %q, want a line for each run", data)
+	}
+}
+
+func TestSetupFileOverWriter(t *testing.T) {
+	var buf bytes.Buffer
+	path := filepath.Join(t.TempDir(), "debug.log")
+	closeLog, err := setup(t, Options{File: path, Writer: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	LogInfo("to the file")
+	closeLog()
+
+	data, _ := os.ReadFile(path)
+	if buf.Len() > 0 || !strings.Contains(string(data), "to the file") {
+		t.Errorf("wrote %q to the writer and %q to the file, want only the file", buf.String(), data)
+	}
+}
+
+func TestSetupDiscards(t *testing.T) {
+	if _, err := setup(t, Options{}); err != nil {
+		t.Fatalf("Setup with nowhere to log returned %v", err)
+	}
+	if Logger().Enabled(t.Context(), slog.LevelError) {
+		t.Errorf("with nowhere to log, the logger still handles errors")
+	}
+}
@@ -0,0 +1,214 @@
+package math
+
+import (
+	"dicer/pkg/stack"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*****************************************
+* Expression trees and canonical form
+* Example: Canonicalize("( 4 + 3 ) * 2") == "2 * ( 3 + 4 )"
+*****************************************/
+type NodeKind int
+
+const (
+	NODE_NUMBER   NodeKind = iota
+	NODE_SUM               // Operands added, or subtracted when Negated
+	NODE_PRODUCT           // Operands multiplied
+	NODE_QUOTIENT          // The first operand divided by the second
+)
+
+// Node is one part of a parsed expression
+type Node struct {
+	Kind     NodeKind
+	Value    int // For a number
+	Operands []*Node
+	Negated  bool // Subtracted from the sum it's an operand of
+}
+
+func number(value int) *Node {
+	return &Node{Kind: NODE_NUMBER, Value: value}
+}
+
+// binary joins two nodes with op, one of + - * /
+func binary(left *Node, op string, right *Node) *Node {
+	switch op {
+	case "+":
+		return &Node{Kind: NODE_SUM, Operands: []*Node{left, right}}
+	case "-":
+		negated := *right
+		negated.Negated = !negated.Negated
+		return &Node{Kind: NODE_SUM, Operands: []*Node{left, &negated}}
+	case "*":
+		return &Node{Kind: NODE_PRODUCT, Operands: []*Node{left, right}}
+	}
+	return &Node{Kind: NODE_QUOTIENT, Operands: []*Node{left, right}}
+}
+
+// ParseExpression builds the tree for an infix expression such as
+// "( 7 + 5 ) * 2". Each character must be separated by a space.
+func ParseExpression(exp string) (*Node, error) {
+	var stack = &stack.StackList[*Node]{}
+
+	for _, item := range strings.Fields(InfixToPostfix(exp)) {
+		if num, err := strconv.Atoi(item); err == nil {
+			stack.Push(number(num))
+			continue
+		}
+		if !IsOperator(item) {
+			continue
+		}
+
+		right, err := stack.Top()
+		if err != nil {
+			return nil, errors.New("Unbalanced expression")
+		}
+		stack.Pop()
+		left, err := stack.Top()
+		if err != nil {
+			return nil, errors.New("Unbalanced expression")
+		}
+		stack.Pop()
+		stack.Push(binary(left, item, right))
+	}
+
+	root, err := stack.Top()
+	if err != nil {
+		return nil, errors.New("Empty expression")
+	}
+	stack.Pop()
+	if !stack.IsEmpty() {
+		return nil, errors.New("Unbalanced expression")
+	}
+	return root, nil
+}
+
+// Canonicalize rewrites exp in canonical form, so that expressions that only
+// differ in order, grouping or redundant parentheses come out the same
+func Canonicalize(exp string) (string, error) {
+	node, err := ParseExpression(exp)
+	if err != nil {
+		return "", err
+	}
+	return node.Canonical().String(), nil
+}
+
+// Equivalent reports whether a and b have the same canonical form
+func Equivalent(a string, b string) bool {
+	canonicalA, errA := Canonicalize(a)
+	canonicalB, errB := Canonicalize(b)
+	return errA == nil && errB == nil && canonicalA == canonicalB
+}
+
+// Canonical returns a copy of the tree with sums and products flattened and
+// their operands sorted. Division truncates, so a quotient is never merged
+// into the product around it and keeps its operands in order.
+func (n *Node) Canonical() *Node {
+	switch n.Kind {
+	case NODE_SUM, NODE_PRODUCT:
+		canonical := &Node{Kind: n.Kind, Negated: n.Negated}
+		n.flatten(n.Kind, false, &canonical.Operands)
+		slices.SortStableFunc(canonical.Operands, compareOperands)
+		return canonical
+	case NODE_QUOTIENT:
+		return &Node{
+			Kind:     NODE_QUOTIENT,
+			Negated:  n.Negated,
+			Operands: []*Node{n.Operands[0].Canonical(), n.Operands[1].Canonical()},
+		}
+	}
+	canonical := *n
+	return &canonical
+}
+
+// flatten gathers the canonical operands of a chain of kind, carrying the
+// sign of nested sums down to their operands
+func (n *Node) flatten(kind NodeKind, negated bool, operands *[]*Node) {
+	for _, operand := range n.Operands {
+		sign := negated != operand.Negated
+		if operand.Kind == kind {
+			operand.flatten(kind, sign, operands)
+			continue
+		}
+
+		canonical := operand.Canonical()
+		canonical.Negated = sign
+		*operands = append(*operands, canonical)
+	}
+}
+
+// compareOperands puts added operands before subtracted ones, numbers before
+// anything grouped, and otherwise orders by value or text
+func compareOperands(a *Node, b *Node) int {
+	if a.Negated != b.Negated {
+		if b.Negated {
+			return -1
+		}
+		return 1
+	}
+
+	aNumber, bNumber := a.Kind == NODE_NUMBER, b.Kind == NODE_NUMBER
+	switch {
+	case aNumber && bNumber:
+		return a.Value - b.Value
+	case aNumber:
+		return -1
+	case bNumber:
+		return 1
+	}
+	return strings.Compare(a.String(), b.String())
+}
+
+// String prints the expression with a space between each character and only
+// the parentheses it needs
+func (n *Node) String() string {
+	switch n.Kind {
+	case NODE_SUM:
+		var b strings.Builder
+		for i, operand := range n.Operands {
+			switch {
+			case operand.Negated:
+				b.WriteString(" - ")
+			case i > 0:
+				b.WriteString(" + ")
+			}
+			b.WriteString(operand.group(operand.Kind == NODE_SUM))
+		}
+		return strings.TrimPrefix(b.String(), " ")
+
+	case NODE_PRODUCT:
+		// A quotient at the front is worked out first, so it only needs
+		// parentheses if there's more than one
+		operands := n.Operands
+		if i := slices.IndexFunc(operands, isQuotient); i > 0 {
+			operands = slices.Insert(slices.Delete(slices.Clone(operands), i, i+1), 0, operands[i])
+		}
+
+		parts := make([]string, len(operands))
+		for i, operand := range operands {
+			// a * ( b / c ) truncates differently from a * b / c
+			parts[i] = operand.group(operand.Kind == NODE_SUM || (i > 0 && operand.Kind == NODE_QUOTIENT))
+		}
+		return strings.Join(parts, " * ")
+
+	case NODE_QUOTIENT:
+		left, right := n.Operands[0], n.Operands[1]
+		return left.group(left.Kind == NODE_SUM) + " / " + right.group(right.Kind != NODE_NUMBER)
+	}
+	return strconv.Itoa(n.Value)
+}
+
+func isQuotient(n *Node) bool {
+	return n.Kind == NODE_QUOTIENT
+}
+
+// group prints the node, in parentheses when parens is set
+func (n *Node) group(parens bool) string {
+	if parens {
+		return "( " + n.String() + " )"
+	}
+	return n.String()
+}
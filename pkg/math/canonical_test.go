@@ -0,0 +1,67 @@
+package math
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name string
+		exp  string
+		want string
+	}{
+		{"sum of a product", "( 4 + 3 ) * 2", "2 * ( 3 + 4 )"},
+		{"already canonical", "2 * ( 3 + 4 )", "2 * ( 3 + 4 )"},
+		{"quotient moved to the front", "3 * ( 6 / 4 )", "6 / 4 * 3"},
+		{"subtracting a difference", "1 - ( 2 - 3 )", "1 + 3 - 2"},
+		{"dividing by a product", "6 / ( 2 * 1 )", "6 / ( 1 * 2 )"},
+		{"product of two quotients", "( 6 / 4 ) * ( 9 / 2 )", "6 / 4 * ( 9 / 2 )"},
+		{"product of two quotients swapped", "( 9 / 2 ) * ( 6 / 4 )", "6 / 4 * ( 9 / 2 )"},
+		{"dividing twice", "9 / 2 / 2", "9 / 2 / 2"},
+		{"dividing by a quotient", "9 / ( 2 / 2 )", "9 / ( 2 / 2 )"},
+		{"negative quotient", "( 1 - 6 ) / 4", "( 1 - 6 ) / 4"},
+		{"truncated before multiplying", "8 / 3 * 3", "8 / 3 * 3"},
+		{"sorted before dividing", "5 * 4 / 3 / 2", "4 * 5 / 3 / 2"},
+		{"sum around a product", "( 2 + 3 ) * 4 - 1", "4 * ( 2 + 3 ) - 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.exp)
+			if err != nil {
+				t.Fatalf("Canonicalize(%q) returned %v", tt.exp, err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonicalize(%q) = %q, want %q", tt.exp, got, tt.want)
+			}
+
+			// Division truncates, so moving a quotient must not change the result
+			if value, want := EvaluateExpression(got), EvaluateExpression(tt.exp); value != want {
+				t.Errorf("%q evaluates to %d, but %q evaluates to %d", got, value, tt.exp, want)
+			}
+
+			again, err := Canonicalize(got)
+			if err != nil || again != got {
+				t.Errorf("Canonicalize(%q) = %q, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"( 4 + 3 ) * 2", "2 * ( 3 + 4 )", true},
+		{"3 * ( 6 / 4 )", "6 / 4 * 3", true},
+		{"3 * 6 / 4", "6 / 4 * 3", false},
+		{"1 - ( 2 - 3 )", "1 - 2 + 3", true},
+		{"1 - ( 2 - 3 )", "1 - 2 - 3", false},
+		{"6 / ( 2 * 1 )", "6 / 2 / 1", false},
+	}
+
+	for _, tt := range tests {
+		if got := Equivalent(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equivalent(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -71,6 +71,9 @@ func EvaluatePostfixExpression(input string) (int, error) {
 				value := operand1 * operand2
 				stack.Push(value)
 			case "/":
+				if operand2 == 0 {
+					return -1, errors.New("Division by zero")
+				}
 				value := operand1 / operand2
 				stack.Push(value)
 			}
@@ -159,25 +162,38 @@ func hasEqualOrHigherPrecedence(stack *stack.StackList[string], current string)
 }
 
 func IsBalancedParens(input string) bool {
-	var stack = stack.CreateArrayStack[rune]()
+	return UnbalancedParenIndex(input) == -1
+}
+
+// UnbalancedParenIndex returns the byte index of the first bracket that breaks
+// the balance of input, or -1 when every bracket is matched.
+func UnbalancedParenIndex(input string) int {
+	var stack = stack.CreateArrayStack[int]()
 
-	for _, runeValue := range input {
+	for index, runeValue := range input {
 		switch runeValue {
 		case '{', '(', '[':
-			stack.Push(runeValue)
+			stack.Push(index)
 		case '}', ')', ']':
+			openIndex, err := stack.Top()
+			if err != nil {
+				return index
+			}
 
-			openRune, _ := stack.Top()
-
-			if stack.IsEmpty() || (openRune == '{' && runeValue != '}' || openRune == '[' && runeValue != ']' || openRune == '(' && runeValue != ')') {
-				return false
-			} else {
-				stack.Pop()
+			openRune := input[openIndex]
+			if openRune == '{' && runeValue != '}' || openRune == '[' && runeValue != ']' || openRune == '(' && runeValue != ')' {
+				return index
 			}
+			stack.Pop()
 		}
 	}
 
-	return stack.IsEmpty()
+	// Anything left on the stack was opened but never closed
+	if openIndex, err := stack.Top(); err == nil {
+		return openIndex
+	}
+
+	return -1
 }
 
 func EvaluateExpression(exp string) int {
@@ -0,0 +1,242 @@
+package math
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*****************************************
+* Exhaustive expression solver
+* Example: Solve([]int{3, 4, 6, 2})
+*****************************************/
+type solverTerm struct {
+	value int
+	node  *Node
+}
+
+const ALL_OPERATORS = "+-*/"
+
+// The number of expressions grows very quickly with each value, so the
+// solver finds nothing for more than MAX_SOLVER_DICE of them
+const MAX_SOLVER_DICE = 6
+
+func canSolve(values []int) bool {
+	return len(values) > 0 && len(values) <= MAX_SOLVER_DICE
+}
+
+// Solve finds every value that can be made by combining all of values with
+// + - * / and parentheses, mapped to one expression that produces it in
+// canonical form. Division truncates the same way EvaluateExpression does.
+func Solve(values []int) map[int]string {
+	return SolveUsing(values, ALL_OPERATORS)
+}
+
+// SolveUsing is Solve limited to the operators in operators, such as "+-"
+func SolveUsing(values []int, operators string) map[int]string {
+	solutions := make(map[int]string)
+	if !canSolve(values) {
+		return solutions
+	}
+
+	searchTerms(createTerms(values), operators, make(map[string]struct{}), func(term solverTerm) bool {
+		if _, found := solutions[term.value]; !found {
+			solutions[term.value] = term.node.Canonical().String()
+		}
+		return false
+	})
+
+	return solutions
+}
+
+// ReachAny looks for an expression that reaches any of targets, stopping at
+// the first one it finds. It returns that target and the expression in
+// canonical form, or false when none of them can be reached.
+func ReachAny(values []int, targets []int, operators string) (int, string, bool) {
+	if !canSolve(values) {
+		return 0, "", false
+	}
+
+	var target int
+	var exp string
+	found := searchTerms(createTerms(values), operators, make(map[string]struct{}), func(term solverTerm) bool {
+		if !slices.Contains(targets, term.value) {
+			return false
+		}
+		target, exp = term.value, term.node.Canonical().String()
+		return true
+	})
+	return target, exp, found
+}
+
+// DistinctSolutions lists the different expressions that reach each value,
+// using only the operators in operators. Expressions with the same canonical
+// form count once, so 3 + 4 and 4 + 3 aren't listed twice.
+func DistinctSolutions(values []int, operators string) map[int][]string {
+	if !canSolve(values) {
+		return map[int][]string{}
+	}
+
+	found := make(map[int]map[string]struct{})
+	combineTerms(createTerms(values), operators, func(term solverTerm) {
+		if found[term.value] == nil {
+			found[term.value] = make(map[string]struct{})
+		}
+		found[term.value][term.node.Canonical().String()] = struct{}{}
+	})
+
+	solutions := make(map[int][]string, len(found))
+	for value, expressions := range found {
+		for exp := range expressions {
+			solutions[value] = append(solutions[value], exp)
+		}
+		slices.Sort(solutions[value])
+	}
+	return solutions
+}
+
+// CountSolutions counts the distinct expressions that reach each value,
+// using only the operators in operators. Like DistinctSolutions, expressions
+// with the same canonical form count once.
+func CountSolutions(values []int, operators string) map[int]int {
+	expressions := make(map[int]map[string]struct{})
+	if !canSolve(values) {
+		return map[int]int{}
+	}
+
+	combineTerms(createTerms(values), operators, func(term solverTerm) {
+		if expressions[term.value] == nil {
+			expressions[term.value] = make(map[string]struct{})
+		}
+		expressions[term.value][term.node.Canonical().String()] = struct{}{}
+	})
+
+	counts := make(map[int]int, len(expressions))
+	for value, found := range expressions {
+		counts[value] = len(found)
+	}
+	return counts
+}
+
+// IsReachable reports whether target can be made from values
+func IsReachable(values []int, target int) bool {
+	return IsReachableUsing(values, target, ALL_OPERATORS)
+}
+
+// IsReachableUsing is IsReachable limited to the operators in operators
+func IsReachableUsing(values []int, target int, operators string) bool {
+	_, _, found := ReachAny(values, []int{target}, operators)
+	return found
+}
+
+func createTerms(values []int) []solverTerm {
+	terms := make([]solverTerm, len(values))
+	for i, value := range values {
+		terms[i] = solverTerm{value, number(value)}
+	}
+	return terms
+}
+
+// combineTerms repeatedly replaces a pair of terms with every way of combining
+// them until a single term is left, which is handed to emit.
+func combineTerms(terms []solverTerm, operators string, emit func(solverTerm)) {
+	if len(terms) == 1 {
+		emit(terms[0])
+		return
+	}
+
+	for i := 0; i < len(terms); i++ {
+		for j := i + 1; j < len(terms); j++ {
+			rest := withoutPair(terms, i, j)
+			for _, combined := range combinePair(terms[i], terms[j], operators) {
+				combineTerms(append(rest, combined), operators, emit)
+			}
+		}
+	}
+}
+
+// searchTerms is combineTerms for when any one expression for a value will
+// do. Every order of the same values reaches the same results, so each set of
+// values left is only searched once. The search stops as soon as emit returns
+// true, and searchTerms reports whether it did.
+func searchTerms(terms []solverTerm, operators string, searched map[string]struct{}, emit func(solverTerm) bool) bool {
+	if len(terms) == 1 {
+		return emit(terms[0])
+	}
+
+	key := termsKey(terms)
+	if _, done := searched[key]; done {
+		return false
+	}
+	searched[key] = struct{}{}
+
+	for i := 0; i < len(terms); i++ {
+		for j := i + 1; j < len(terms); j++ {
+			rest := withoutPair(terms, i, j)
+			for _, combined := range combinePair(terms[i], terms[j], operators) {
+				if searchTerms(append(rest, combined), operators, searched, emit) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// withoutPair copies terms without the terms at i and j, leaving room for the
+// term they combine into
+func withoutPair(terms []solverTerm, i, j int) []solverTerm {
+	rest := make([]solverTerm, 0, len(terms)-1)
+	for k := range terms {
+		if k != i && k != j {
+			rest = append(rest, terms[k])
+		}
+	}
+	return rest
+}
+
+// termsKey names the values of terms regardless of their order
+func termsKey(terms []solverTerm) string {
+	values := make([]int, len(terms))
+	for i, term := range terms {
+		values[i] = term.value
+	}
+	slices.Sort(values)
+
+	var key strings.Builder
+	for _, value := range values {
+		key.WriteString(strconv.Itoa(value))
+		key.WriteByte(' ')
+	}
+	return key.String()
+}
+
+func combinePair(a solverTerm, b solverTerm, operators string) []solverTerm {
+	join := func(value int, left solverTerm, op string, right solverTerm) solverTerm {
+		return solverTerm{value, binary(left.node, op, right.node)}
+	}
+
+	var combined []solverTerm
+	if strings.Contains(operators, "+") {
+		combined = append(combined, join(a.value+b.value, a, "+", b))
+	}
+	if strings.Contains(operators, "*") {
+		combined = append(combined, join(a.value*b.value, a, "*", b))
+	}
+	if strings.Contains(operators, "-") {
+		combined = append(combined,
+			join(a.value-b.value, a, "-", b),
+			join(b.value-a.value, b, "-", a),
+		)
+	}
+	if strings.Contains(operators, "/") {
+		if b.value != 0 {
+			combined = append(combined, join(a.value/b.value, a, "/", b))
+		}
+		if a.value != 0 {
+			combined = append(combined, join(b.value/a.value, b, "/", a))
+		}
+	}
+
+	return combined
+}
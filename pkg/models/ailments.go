@@ -1,41 +1,174 @@
 package models
 
-import "dicer/pkg/config"
-
 /*************************************
 * Ailments
 *************************************/
+type AilmentKind string
+
+const (
+	AILMENT_NORMAL    AilmentKind = "normal"
+	AILMENT_TOUGH     AilmentKind = "tough"     // Needs more than one hit
+	AILMENT_CURSED    AilmentKind = "cursed"    // Costs a life unless it's the last one left
+	AILMENT_SPREADING AilmentKind = "spreading" // Spreads to a neighbouring value if left too long
+)
+
+const DEFAULT_TOUGH_HITS = 2
+const DEFAULT_SPREAD_TURNS = 3
+
+type Ailment struct {
+	Value       int         `json:"value"`
+	Kind        AilmentKind `json:"kind,omitempty"`
+	Hits        int         `json:"hits,omitempty"`        // Hits still needed to remove it
+	SpreadEvery int         `json:"spreadEvery,omitempty"` // Turns between spreading
+	Spread      int         `json:"spread,omitempty"`      // Turns until it next spreads
+	Removed     bool        `json:"removed,omitempty"`
+}
+
+func (a Ailment) IsActive() bool {
+	return !a.Removed
+}
+
 type Ailments struct {
-	Remaining []int
+	List []Ailment `json:"list"`
 }
 
+// CreateAilments makes the classic ailments, 1 to num
 func CreateAilments(num int) *Ailments {
-	slice := make([]int, num)
+	specs := make([]Ailment, num)
+	for i := range specs {
+		specs[i] = Ailment{Value: i + 1}
+	}
+
+	return CreateAilmentsFrom(specs)
+}
 
-	for i := range slice {
-		slice[i] = i + 1
+// CreateAilmentsFrom copies specs, filling in the defaults for each kind
+func CreateAilmentsFrom(specs []Ailment) *Ailments {
+	list := make([]Ailment, len(specs))
+	for i, spec := range specs {
+		if spec.Kind == "" {
+			spec.Kind = AILMENT_NORMAL
+		}
+		if spec.Hits < 1 {
+			spec.Hits = 1
+			if spec.Kind == AILMENT_TOUGH {
+				spec.Hits = DEFAULT_TOUGH_HITS
+			}
+		}
+		if spec.Kind == AILMENT_SPREADING {
+			if spec.SpreadEvery < 1 {
+				spec.SpreadEvery = DEFAULT_SPREAD_TURNS
+			}
+			if spec.Spread < 1 {
+				spec.Spread = spec.SpreadEvery
+			}
+		}
+		list[i] = spec
 	}
 
-	ailments := &Ailments{Remaining: slice}
-	return ailments
+	return &Ailments{List: list}
 }
 
 func (ailments *Ailments) HasAilments() bool {
-	for a := range ailments.Remaining {
-		if ailments.Remaining[a] != config.RemovedAilmentValue {
-			return true
+	return ailments.numActive() > 0
+}
+
+func (ailments *Ailments) HasAilment(value int) bool {
+	return ailments.find(value) != -1
+}
+
+// Values lists the values of the ailments still in play
+func (ailments *Ailments) Values() []int {
+	var values []int
+	for _, a := range ailments.List {
+		if a.IsActive() {
+			values = append(values, a.Value)
 		}
 	}
-	return false
+	return values
+}
+
+func (ailments *Ailments) numActive() int {
+	return len(ailments.Values())
 }
 
-func (ailments *Ailments) HasAilment(num int) bool {
-	if len(ailments.Remaining) < num || num < 1 {
-		return false
+// find returns the index of the active ailment with value, or -1
+func (ailments *Ailments) find(value int) int {
+	for i, a := range ailments.List {
+		if a.IsActive() && a.Value == value {
+			return i
+		}
 	}
-	return ailments.Remaining[num-1] != config.RemovedAilmentValue
+	return -1
 }
 
-func (ailments *Ailments) RemoveAilment(result int) {
-	ailments.Remaining[result-1] = config.RemovedAilmentValue
+type HitResult struct {
+	Found   bool // An ailment had the value
+	Removed bool // The hit cleared it
+	Cursed  bool // The hit costs a life
+	Kind    AilmentKind
+}
+
+// Hit strikes the ailment with value, if there is one
+func (ailments *Ailments) Hit(value int) HitResult {
+	index := ailments.find(value)
+	if index == -1 {
+		return HitResult{}
+	}
+
+	ailment := &ailments.List[index]
+	result := HitResult{
+		Found:  true,
+		Cursed: ailment.Kind == AILMENT_CURSED && ailments.numActive() > 1,
+		Kind:   ailment.Kind,
+	}
+
+	ailment.Hits--
+	if ailment.Hits <= 0 {
+		ailment.Removed = true
+		result.Removed = true
+	}
+
+	return result
+}
+
+// Spread counts down the spreading ailments at the end of a turn. Any that
+// run out infect the next value up, or down if that's taken, and the values
+// they spread to are returned.
+func (ailments *Ailments) Spread() []int {
+	var spread []int
+
+	for i := range ailments.List {
+		a := &ailments.List[i]
+		if !a.IsActive() || a.Kind != AILMENT_SPREADING {
+			continue
+		}
+
+		a.Spread--
+		if a.Spread > 0 {
+			continue
+		}
+		a.Spread = a.SpreadEvery
+
+		for _, value := range []int{a.Value + 1, a.Value - 1} {
+			if value >= 1 && !ailments.HasAilment(value) {
+				ailments.infect(value)
+				spread = append(spread, value)
+				break
+			}
+		}
+	}
+
+	return spread
+}
+
+// infect brings back a removed ailment with value, or adds a new one
+func (ailments *Ailments) infect(value int) {
+	for i := range ailments.List {
+		if ailments.List[i].Value == value {
+			ailments.List[i] = Ailment{Value: value, Kind: AILMENT_NORMAL, Hits: 1}
+			return
+		}
+	}
+	ailments.List = append(ailments.List, Ailment{Value: value, Kind: AILMENT_NORMAL, Hits: 1})
 }
@@ -1,20 +1,108 @@
 package models
 
-import "math/rand/v2"
+import (
+	"dicer/pkg/config"
+	"math/rand/v2"
+)
 
 /*************************************
 * Dice
 *************************************/
 type Dice struct {
 	Value int
+	Sides int
 }
 
 func (d *Dice) Roll() {
-	d.Value = rand.IntN(6) + 1
+	d.RollFrom(RandomSource{})
+}
+
+func (d *Dice) RollFrom(source DiceSource) {
+	if d.Sides < 1 {
+		d.Sides = config.DiceSides
+	}
+	d.Value = source.Roll(d.Sides)
 }
 
 func CreateAndRollDie() Dice {
-	die := Dice{}
-	die.Roll()
+	return CreateAndRollDieFrom(RandomSource{})
+}
+
+func CreateAndRollDieFrom(source DiceSource) Dice {
+	die := Dice{Sides: config.DiceSides}
+	die.RollFrom(source)
 	return die
 }
+
+/*************************************
+* Dice Sources
+*************************************/
+// DiceSource decides what a die with the given number of sides lands on
+type DiceSource interface {
+	Roll(sides int) int
+}
+
+type RandomSource struct{}
+
+func (RandomSource) Roll(sides int) int {
+	return rand.IntN(sides) + 1
+}
+
+// SeededSource rolls the same sequence of values every time for a seed
+type SeededSource struct {
+	Seed  uint64
+	rng   *rand.Rand
+	rolls int
+}
+
+func CreateSeededSource(seed uint64) *SeededSource {
+	return &SeededSource{Seed: seed, rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// NewSeed picks a random seed for a game that wasn't given one, so it can
+// still be replayed. It's never 0, which callers use to mean no seed.
+func NewSeed() uint64 {
+	for {
+		if seed := rand.Uint64(); seed != 0 {
+			return seed
+		}
+	}
+}
+
+func (s *SeededSource) Roll(sides int) int {
+	s.rolls++
+	return s.rng.IntN(sides) + 1
+}
+
+// Rolls counts the values rolled so far
+func (s *SeededSource) Rolls() int {
+	return s.rolls
+}
+
+// ScriptedSource replays fixed values in order, starting over when it runs
+// out. Values bigger than the die are wrapped onto it.
+type ScriptedSource struct {
+	Values []int
+	next   int
+}
+
+func CreateScriptedSource(values ...int) *ScriptedSource {
+	return &ScriptedSource{Values: values}
+}
+
+func (s *ScriptedSource) Roll(sides int) int {
+	if len(s.Values) == 0 {
+		return 1
+	}
+
+	value := s.Values[s.next%len(s.Values)]
+	s.next++
+
+	return (value-1)%sides + 1
+}
+
+// Rolls counts the values rolled so far, and keeps counting after the values
+// start over
+func (s *ScriptedSource) Rolls() int {
+	return s.next
+}
@@ -0,0 +1,166 @@
+package models
+
+import "fmt"
+
+/*************************************
+* Items
+*************************************/
+type ItemKind string
+
+const (
+	ITEM_NUDGE     ItemKind = "nudge"
+	ITEM_MIRROR    ItemKind = "mirror"
+	ITEM_EXTRA_DIE ItemKind = "extra-die"
+	ITEM_SHIELD    ItemKind = "shield"
+	ITEM_WILD      ItemKind = "wild"
+)
+
+// The most items a player can carry at once
+const MAX_ITEMS = 5
+
+// ItemNeeds says what the player has to pick before an item can be used
+type ItemNeeds int
+
+const (
+	NEEDS_NOTHING ItemNeeds = iota
+	NEEDS_DIE               // A die to use it on
+	NEEDS_VALUE             // A die and a value, such as the direction of a nudge
+)
+
+// ItemUse is what the player picked when using an item. Items only read the
+// fields their ItemNeeds ask for.
+type ItemUse struct {
+	Die   int `json:"die"` // Index into Turn.Dice
+	Value int `json:"value"`
+}
+
+// Effect is what an item does to a turn. Each kind of item is its own type,
+// so a new item only needs an Effect and a place in Items.
+type Effect interface {
+	Kind() ItemKind
+	Name() string
+	Description() string
+	Needs() ItemNeeds
+	Apply(turn *Turn, use ItemUse) error
+}
+
+// Items lists every effect in the order they're handed out
+var Items = []Effect{Nudge{}, Mirror{}, ExtraDie{}, Shield{}, Wild{}}
+
+func LookupItem(kind ItemKind) (Effect, bool) {
+	for _, effect := range Items {
+		if effect.Kind() == kind {
+			return effect, true
+		}
+	}
+	return nil, false
+}
+
+// turnDie returns the die at index, if the turn has one there
+func turnDie(turn *Turn, index int) (*Dice, error) {
+	if index < 0 || index >= len(turn.Dice) {
+		return nil, fmt.Errorf("there is no die %d", index+1)
+	}
+	return &turn.Dice[index], nil
+}
+
+// Nudge moves one die up or down by one
+type Nudge struct{}
+
+func (Nudge) Kind() ItemKind      { return ITEM_NUDGE }
+func (Nudge) Name() string        { return "Nudge" }
+func (Nudge) Description() string { return "Move one die up or down by 1" }
+func (Nudge) Needs() ItemNeeds    { return NEEDS_VALUE }
+
+func (Nudge) Apply(turn *Turn, use ItemUse) error {
+	die, err := turnDie(turn, use.Die)
+	if err != nil {
+		return err
+	}
+	if use.Value != 1 && use.Value != -1 {
+		return fmt.Errorf("a die can only be nudged by 1 or -1")
+	}
+
+	value := die.Value + use.Value
+	if value < 1 || value > die.Sides {
+		return fmt.Errorf("a %d can't be nudged to %d", die.Value, value)
+	}
+	die.Value = value
+	return nil
+}
+
+// Mirror turns a die over to its opposite face, so a 2 on a d6 becomes a 5
+type Mirror struct{}
+
+func (Mirror) Kind() ItemKind      { return ITEM_MIRROR }
+func (Mirror) Name() string        { return "Mirror" }
+func (Mirror) Description() string { return "Flip one die to its opposite face" }
+func (Mirror) Needs() ItemNeeds    { return NEEDS_DIE }
+
+func (Mirror) Apply(turn *Turn, use ItemUse) error {
+	die, err := turnDie(turn, use.Die)
+	if err != nil {
+		return err
+	}
+	die.Value = die.Sides + 1 - die.Value
+	return nil
+}
+
+// ExtraDie rolls one more die for this turn only
+type ExtraDie struct{}
+
+func (ExtraDie) Kind() ItemKind      { return ITEM_EXTRA_DIE }
+func (ExtraDie) Name() string        { return "Extra Die" }
+func (ExtraDie) Description() string { return "Roll one more die this turn" }
+func (ExtraDie) Needs() ItemNeeds    { return NEEDS_NOTHING }
+
+func (ExtraDie) Apply(turn *Turn, use ItemUse) error {
+	if turn.HasUsed(ITEM_EXTRA_DIE) {
+		return fmt.Errorf("only one extra die can be added each turn")
+	}
+
+	sides := 0
+	if len(turn.Dice) > 0 {
+		sides = turn.Dice[0].Sides
+	}
+	die := Dice{Sides: sides}
+	die.RollFrom(turn.Source)
+	turn.Dice = append(turn.Dice, die)
+	return nil
+}
+
+// Shield stops the next life from being lost this turn
+type Shield struct{}
+
+func (Shield) Kind() ItemKind      { return ITEM_SHIELD }
+func (Shield) Name() string        { return "Shield" }
+func (Shield) Description() string { return "Don't lose a life this turn" }
+func (Shield) Needs() ItemNeeds    { return NEEDS_NOTHING }
+
+func (Shield) Apply(turn *Turn, use ItemUse) error {
+	if turn.Shield {
+		return fmt.Errorf("this turn is already shielded")
+	}
+	turn.Shield = true
+	return nil
+}
+
+// Wild sets one die to any of its faces
+type Wild struct{}
+
+func (Wild) Kind() ItemKind      { return ITEM_WILD }
+func (Wild) Name() string        { return "Wild" }
+func (Wild) Description() string { return "Set one die to any value" }
+func (Wild) Needs() ItemNeeds    { return NEEDS_VALUE }
+
+func (Wild) Apply(turn *Turn, use ItemUse) error {
+	die, err := turnDie(turn, use.Die)
+	if err != nil {
+		return err
+	}
+	if use.Value < 1 || use.Value > die.Sides {
+		return fmt.Errorf("a d%d can't show %d", die.Sides, use.Value)
+	}
+	die.Value = use.Value
+	return nil
+}
@@ -4,8 +4,11 @@ package models
 * Player
 *************************************/
 type Player struct {
-	Lives    int
-	Ailments *Ailments
+	Lives    int        `json:"lives"`
+	Ailments *Ailments  `json:"ailments"`
+	Items    []ItemKind `json:"items,omitempty"`
+	Streak   int        `json:"streak"` // Ailments removed in a row
+	Score    int        `json:"score"`
 }
 
 func CreatePlayer(numLives int, numAilments int) Player {
@@ -15,10 +18,32 @@ func CreatePlayer(numLives int, numAilments int) Player {
 	return *player
 }
 
+func CreatePlayerWith(numLives int, ailments *Ailments) Player {
+	return Player{Lives: numLives, Ailments: ailments}
+}
+
 func (player *Player) HasLives() bool {
 	return player.Lives > 0
 }
 
 func (player *Player) RemoveLife() {
-	player.Lives--
+	player.RemoveLives(1)
+}
+
+func (player *Player) RemoveLives(lives int) {
+	player.Lives = max(player.Lives-lives, 0)
+}
+
+// AddItem puts an item in the inventory, unless it's already full
+func (player *Player) AddItem(kind ItemKind) bool {
+	if len(player.Items) >= MAX_ITEMS {
+		return false
+	}
+	player.Items = append(player.Items, kind)
+	return true
+}
+
+// TakeItem removes the item at index from the inventory
+func (player *Player) TakeItem(index int) {
+	player.Items = append(player.Items[:index], player.Items[index+1:]...)
 }
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*************************************
+* Result Policies
+*************************************/
+const (
+	POLICY_STANDARD  = "standard"
+	POLICY_NEAR_MISS = "nearmiss"
+	POLICY_PASS      = "pass"
+	POLICY_BUST      = "bust"
+)
+
+// ResultPolicy decides what a missed result costs. Each policy is its own
+// type, so a new one only needs a place in Policies.
+type ResultPolicy interface {
+	Name() string
+	Description() string
+	// MissCost is how many lives result costs when it hits nothing, with a
+	// note explaining it when the policy changed the usual cost
+	MissCost(result int, ailments *Ailments) (int, string)
+	// ChallengeCost is how many lives a challenge costs when an ailment
+	// could have been reached
+	ChallengeCost() int
+}
+
+var Policies = []ResultPolicy{StandardPolicy{}, NearMissPolicy{}, PassPolicy{}, BustPolicy{}}
+
+// LookupPolicy finds the policy called name. No name means the standard one.
+func LookupPolicy(name string) (ResultPolicy, bool) {
+	if name == "" {
+		name = POLICY_STANDARD
+	}
+	for _, policy := range Policies {
+		if policy.Name() == name {
+			return policy, true
+		}
+	}
+	return nil, false
+}
+
+func PolicyNames() []string {
+	names := make([]string, len(Policies))
+	for i, policy := range Policies {
+		names[i] = policy.Name()
+	}
+	return names
+}
+
+// StandardPolicy costs a life for every miss
+type StandardPolicy struct{}
+
+func (StandardPolicy) Name() string        { return POLICY_STANDARD }
+func (StandardPolicy) Description() string { return "Every miss costs a life" }
+func (StandardPolicy) ChallengeCost() int  { return 1 }
+
+func (StandardPolicy) MissCost(result int, ailments *Ailments) (int, string) {
+	return 1, ""
+}
+
+// NearMissPolicy forgives results one away from an ailment
+type NearMissPolicy struct{}
+
+func (NearMissPolicy) Name() string        { return POLICY_NEAR_MISS }
+func (NearMissPolicy) Description() string { return "Missing an ailment by 1 costs nothing" }
+func (NearMissPolicy) ChallengeCost() int  { return 1 }
+
+func (NearMissPolicy) MissCost(result int, ailments *Ailments) (int, string) {
+	for _, value := range []int{result - 1, result + 1} {
+		if ailments.HasAilment(value) {
+			return 0, fmt.Sprintf("Near miss: %d is one away from %d, so it costs nothing.", result, value)
+		}
+	}
+	return 1, ""
+}
+
+// PassPolicy raises the stakes of challenging a roll
+type PassPolicy struct{}
+
+const PASS_PENALTY = 2
+
+func (PassPolicy) Name() string { return POLICY_PASS }
+func (PassPolicy) Description() string {
+	return fmt.Sprintf("Challenging a roll that could reach an ailment costs %d lives", PASS_PENALTY)
+}
+func (PassPolicy) ChallengeCost() int { return PASS_PENALTY }
+
+func (PassPolicy) MissCost(result int, ailments *Ailments) (int, string) {
+	return 1, ""
+}
+
+// BustPolicy doubles the cost of overshooting every ailment
+type BustPolicy struct{}
+
+func (BustPolicy) Name() string        { return POLICY_BUST }
+func (BustPolicy) Description() string { return "Going over the highest ailment costs 2 lives" }
+func (BustPolicy) ChallengeCost() int  { return 1 }
+
+func (BustPolicy) MissCost(result int, ailments *Ailments) (int, string) {
+	values := ailments.Values()
+	if len(values) > 0 && result > slices.Max(values) {
+		return 2, fmt.Sprintf("Bust: %d is over the highest ailment, %d, so it costs 2 lives.", result, slices.Max(values))
+	}
+	return 1, ""
+}
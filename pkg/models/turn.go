@@ -1,8 +1,8 @@
 package models
 
 import (
-	"dicer/pkg/config"
 	"dicer/pkg/stack"
+	"slices"
 )
 
 /*************************************
@@ -21,20 +21,85 @@ const (
 	GS_GameOver                  // Game over state
 )
 
+var phaseNames = map[TurnPhase]string{
+	GS_TurnStart:       "start",
+	GS_RollPhase:       "roll",
+	GS_ExpressionPhase: "expression",
+	GS_ResultsPhase:    "results",
+	GS_GameOver:        "over",
+}
+
+var phaseActions = map[TurnPhase]string{
+	GS_TurnStart:       "roll the dice",
+	GS_RollPhase:       "choose dice to re-roll",
+	GS_ExpressionPhase: "submit an expression",
+	GS_ResultsPhase:    "continue to the next turn",
+	GS_GameOver:        "start a new game",
+}
+
+func (p TurnPhase) String() string {
+	if name, ok := phaseNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Action describes what the player does next in this phase
+func (p TurnPhase) Action() string {
+	return phaseActions[p]
+}
+
+// PartResult is how one part of a combo scored. An expression without
+// commas is a single part. Canonical is the expression in canonical form,
+// and Repeat marks a part that was also a solution last turn.
+type PartResult struct {
+	Expression string `json:"expression"`
+	Canonical  string `json:"canonical"`
+	Result     int    `json:"result"`
+	Hit        bool   `json:"hit"`
+	Removed    bool   `json:"removed"`
+	Cursed     bool   `json:"cursed,omitempty"`
+	Repeat     bool   `json:"repeat,omitempty"`
+}
+
+// Points for each part, before the combo bonus
+const (
+	SCORE_HIT     = 5
+	SCORE_REMOVED = 10
+)
+
+// Turn is saved as JSON without its phase stack and dice source, which the
+// loader rebuilds
 type Turn struct {
-	Round          int
-	Dice           []Dice
-	Result         int
-	Expression     string
-	RemovedAilment bool
-	LostLife       bool
-	Stack          *stack.ArrayStack[TurnPhase]
+	Round          int                          `json:"round"`
+	Dice           []Dice                       `json:"dice"`
+	Result         int                          `json:"result"` // The first part's result
+	Expression     string                       `json:"expression"`
+	Parts          []PartResult                 `json:"parts,omitempty"`
+	Score          int                          `json:"score"` // Points this turn
+	HitAilment     bool                         `json:"hitAilment"`
+	RemovedAilment bool                         `json:"removedAilment"`
+	Cursed         bool                         `json:"cursed"`
+	LostLife       bool                         `json:"lostLife"`
+	LivesLost      int                          `json:"livesLost"`
+	PolicyNote     string                       `json:"policyNote,omitempty"` // Why the result policy changed what a miss cost
+	Challenged     bool                         `json:"challenged"`           // The player claimed nothing could be reached
+	Reveal         string                       `json:"reveal,omitempty"`     // An expression that would have hit, after a wrong challenge
+	Cleared        []AilmentKind                `json:"cleared,omitempty"`    // Kinds of the ailments removed this turn
+	Spread         []int                        `json:"spread,omitempty"`     // Ailments that spread as this turn began
+	ItemsUsed      []ItemKind                   `json:"itemsUsed,omitempty"`
+	Shield         bool                         `json:"shield"`   // A shield is up for this turn
+	Shielded       bool                         `json:"shielded"` // The shield saved a life
+	EarnedItem     ItemKind                     `json:"earnedItem,omitempty"`
+	Stack          *stack.ArrayStack[TurnPhase] `json:"-"`
+	Source         DiceSource                   `json:"-"`
 }
 
-func CreateTurn(round int) *Turn {
+func CreateTurn(round int, source DiceSource) *Turn {
 	turn := &Turn{
-		Round: round,
-		Stack: CreateTurnStack(),
+		Round:  round,
+		Stack:  CreateTurnStack(),
+		Source: source,
 	}
 
 	return turn
@@ -51,10 +116,11 @@ func CreateTurnStack() *stack.ArrayStack[TurnPhase] {
 	return stack
 }
 
-func (t *Turn) RollDice() {
-	dice := make([]Dice, config.NumDice)
+func (t *Turn) RollDice(numDice int, sides int) {
+	dice := make([]Dice, numDice)
 	for i := range dice {
-		dice[i] = CreateAndRollDie()
+		dice[i] = Dice{Sides: sides}
+		dice[i].RollFrom(t.Source)
 	}
 
 	t.Dice = dice
@@ -62,16 +128,103 @@ func (t *Turn) RollDice() {
 
 func (t *Turn) RollSelectedDice(selected map[int]struct{}) {
 	for i := range selected {
-		t.Dice[i].Roll()
+		t.Dice[i].RollFrom(t.Source)
 	}
 }
 
-func (t *Turn) ApplyResult(player *Player) {
-	if player.Ailments.HasAilment(t.Result) {
-		player.Ailments.RemoveAilment(t.Result)
-		t.RemovedAilment = true
+// HasUsed reports whether an item of kind was used this turn
+func (t *Turn) HasUsed(kind ItemKind) bool {
+	return slices.Contains(t.ItemsUsed, kind)
+}
+
+// ApplyResult hits an ailment with each part's result in order. The policy
+// prices each miss, and the turn costs the most expensive one, however many
+// parts missed. Every part that hits multiplies the turn's points, but a
+// repeated part earns none of its own. A turn where any part missed ends the
+// streak, whatever order the parts came in, and otherwise every ailment it
+// removed adds to it.
+func (t *Turn) ApplyResult(player *Player, policy ResultPolicy) {
+	cost := 0
+	points := 0
+	hits := 0
+	missed := false
+
+	for i := range t.Parts {
+		part := &t.Parts[i]
+		hit := player.Ailments.Hit(part.Result)
+		part.Hit = hit.Found
+		part.Removed = hit.Removed
+		part.Cursed = hit.Cursed
+
+		t.HitAilment = t.HitAilment || hit.Found
+		t.RemovedAilment = t.RemovedAilment || hit.Removed
+		t.Cursed = t.Cursed || hit.Cursed
+		if hit.Cursed {
+			cost = max(cost, 1)
+		}
+		if !hit.Found {
+			missed = true
+			partCost, note := policy.MissCost(part.Result, player.Ailments)
+			if note != "" {
+				t.PolicyNote = note
+			}
+			cost = max(cost, partCost)
+		}
+
+		switch {
+		case hit.Removed:
+			t.Cleared = append(t.Cleared, hit.Kind)
+			points += partPoints(part, SCORE_REMOVED)
+			hits++
+		case hit.Found:
+			points += partPoints(part, SCORE_HIT)
+			hits++
+		}
+	}
+
+	if missed {
+		player.Streak = 0
 	} else {
-		player.RemoveLife()
-		t.LostLife = true
+		player.Streak += len(t.Cleared)
 	}
+
+	t.Score = points * max(hits, 1)
+	player.Score += t.Score
+	t.loseLives(player, cost)
+}
+
+func partPoints(part *PartResult, points int) int {
+	if part.Repeat {
+		return 0
+	}
+	return points
+}
+
+// ApplyChallenge scores a claim that the roll can't reach any ailment. An
+// empty reveal means the claim was right, so the turn is skipped for free.
+// Otherwise it costs cost lives.
+func (t *Turn) ApplyChallenge(player *Player, reveal string, cost int) {
+	t.Challenged = true
+	t.Reveal = reveal
+	if reveal == "" {
+		return
+	}
+
+	player.Streak = 0
+	t.loseLives(player, cost)
+}
+
+// loseLives takes lives from the player unless a shield is up
+func (t *Turn) loseLives(player *Player, lives int) {
+	if lives == 0 {
+		return
+	}
+	if t.Shield {
+		t.Shielded = true
+		return
+	}
+
+	player.RemoveLives(lives)
+	t.LostLife = true
+	t.LivesLost = lives
 }
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+// parts makes a combo of results, marking those in repeats as repeated from
+// last turn
+func parts(results []int, repeats ...int) []PartResult {
+	parts := make([]PartResult, len(results))
+	for i, result := range results {
+		parts[i] = PartResult{Result: result}
+	}
+	for _, i := range repeats {
+		parts[i].Repeat = true
+	}
+	return parts
+}
+
+func TestApplyResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		parts      []PartResult
+		policy     ResultPolicy
+		streak     int // Before the turn
+		shield     bool
+		wantHits   []bool
+		wantScore  int
+		wantLives  int
+		wantStreak int
+		wantLeft   []int // Ailments still in play
+	}{
+		{
+			name: "single hit", parts: parts([]int{3}), streak: 2,
+			wantHits: []bool{true}, wantScore: 10, wantLives: 3, wantStreak: 3, wantLeft: []int{1, 2, 4, 5},
+		},
+		{
+			name: "single miss", parts: parts([]int{9}), streak: 2,
+			wantHits: []bool{false}, wantScore: 0, wantLives: 2, wantStreak: 0, wantLeft: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "every part hits", parts: parts([]int{3, 4}), streak: 1,
+			wantHits: []bool{true, true}, wantScore: 40, wantLives: 3, wantStreak: 3, wantLeft: []int{1, 2, 5},
+		},
+		{
+			name: "hit then miss", parts: parts([]int{3, 9}), streak: 2,
+			wantHits: []bool{true, false}, wantScore: 10, wantLives: 2, wantStreak: 0, wantLeft: []int{1, 2, 4, 5},
+		},
+		{
+			name: "miss then hit", parts: parts([]int{9, 3}), streak: 2,
+			wantHits: []bool{false, true}, wantScore: 10, wantLives: 2, wantStreak: 0, wantLeft: []int{1, 2, 4, 5},
+		},
+		{
+			name: "two misses cost one life", parts: parts([]int{8, 9}), streak: 2,
+			wantHits: []bool{false, false}, wantScore: 0, wantLives: 2, wantStreak: 0, wantLeft: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name: "the same ailment twice", parts: parts([]int{3, 3}),
+			wantHits: []bool{true, false}, wantScore: 10, wantLives: 2, wantStreak: 0, wantLeft: []int{1, 2, 4, 5},
+		},
+		{
+			name: "repeated part earns nothing itself", parts: parts([]int{3, 4}, 0),
+			wantHits: []bool{true, true}, wantScore: 20, wantLives: 3, wantStreak: 2, wantLeft: []int{1, 2, 5},
+		},
+		{
+			name: "most expensive miss", parts: parts([]int{4, 2, 9}), policy: BustPolicy{},
+			wantHits: []bool{true, true, false}, wantScore: 40, wantLives: 1, wantStreak: 0, wantLeft: []int{1, 3, 5},
+		},
+		{
+			name: "near miss next to a hit", parts: parts([]int{3, 6}), policy: NearMissPolicy{}, streak: 1,
+			wantHits: []bool{true, false}, wantScore: 10, wantLives: 3, wantStreak: 0, wantLeft: []int{1, 2, 4, 5},
+		},
+		{
+			name: "shield saves the life", parts: parts([]int{3, 9}), shield: true, streak: 2,
+			wantHits: []bool{true, false}, wantScore: 10, wantLives: 3, wantStreak: 0, wantLeft: []int{1, 2, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := CreatePlayerWith(3, CreateAilments(5))
+			player.Streak = tt.streak
+			turn := &Turn{Parts: tt.parts, Shield: tt.shield}
+			policy := tt.policy
+			if policy == nil {
+				policy = StandardPolicy{}
+			}
+
+			turn.ApplyResult(&player, policy)
+
+			for i, part := range turn.Parts {
+				if part.Hit != tt.wantHits[i] {
+					t.Errorf("part %d (%d) hit = %v, want %v", i+1, part.Result, part.Hit, tt.wantHits[i])
+				}
+			}
+			if turn.Score != tt.wantScore || player.Score != tt.wantScore {
+				t.Errorf("turn scored %d and the player has %d, want %d", turn.Score, player.Score, tt.wantScore)
+			}
+			if player.Lives != tt.wantLives {
+				t.Errorf("player has %d lives, want %d", player.Lives, tt.wantLives)
+			}
+			if player.Streak != tt.wantStreak {
+				t.Errorf("streak is %d, want %d", player.Streak, tt.wantStreak)
+			}
+			if left := player.Ailments.Values(); !slices.Equal(left, tt.wantLeft) {
+				t.Errorf("ailments left are %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package puzzle
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/math"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+/*************************************
+* Generator
+*************************************/
+type Difficulty string
+
+const (
+	EASY   Difficulty = "easy"
+	MEDIUM Difficulty = "medium"
+	HARD   Difficulty = "hard"
+	EXPERT Difficulty = "expert"
+)
+
+var Difficulties = []Difficulty{EASY, MEDIUM, HARD, EXPERT}
+
+// A target's difficulty comes from how many distinct expressions reach it,
+// told apart by their canonical form. More dice reach every target many more
+// ways, so the ranges are tuned for each number of config.DiceSides-sided
+// dice, giving each difficulty about the same share of targets whatever the
+// number. Two dice reach too few targets too few ways to tell the
+// difficulties apart.
+var solutionRanges = map[int]map[Difficulty][2]int{
+	3: {
+		EASY:   {5, int(^uint(0) >> 1)},
+		MEDIUM: {3, 4},
+		HARD:   {2, 2},
+		EXPERT: {1, 1},
+	},
+	4: {
+		EASY:   {20, int(^uint(0) >> 1)},
+		MEDIUM: {4, 19},
+		HARD:   {2, 3},
+		EXPERT: {1, 1},
+	},
+	5: {
+		EASY:   {360, int(^uint(0) >> 1)},
+		MEDIUM: {73, 359},
+		HARD:   {25, 72},
+		EXPERT: {1, 24},
+	},
+}
+
+type GenerateOptions struct {
+	Seed      uint64
+	Count     int // Puzzles per difficulty
+	NumDice   int
+	Targets   int // Targets per puzzle
+	MaxTarget int
+	Workers   int
+}
+
+func DefaultGenerateOptions() GenerateOptions {
+	return GenerateOptions{
+		Seed:      1,
+		Count:     50,
+		NumDice:   config.NumDice,
+		Targets:   3,
+		MaxTarget: 30,
+		Workers:   runtime.NumCPU(),
+	}
+}
+
+// Solving grows very quickly with the number of dice
+const (
+	MIN_GENERATE_DICE = 3
+	MAX_GENERATE_DICE = 5
+)
+
+func (opts GenerateOptions) Validate() error {
+	switch {
+	case opts.Count < 1:
+		return fmt.Errorf("count must be at least 1")
+	case opts.NumDice < MIN_GENERATE_DICE || opts.NumDice > MAX_GENERATE_DICE:
+		return fmt.Errorf("dice must be between %d and %d", MIN_GENERATE_DICE, MAX_GENERATE_DICE)
+	case opts.Targets < 1:
+		return fmt.Errorf("targets must be at least 1")
+	case opts.MaxTarget < opts.Targets:
+		return fmt.Errorf("max target must be at least the number of targets")
+	}
+	return nil
+}
+
+// Candidates are graded in batches across the workers, then taken in order,
+// so the same seed makes the same packs however many workers there are
+const GENERATE_BATCH_SIZE = 256
+
+// Give up on difficulties the dice can't fill after this many candidates
+const MAX_CANDIDATES = 100_000
+
+// Generate fills a pack for each difficulty with Count puzzles
+func Generate(opts GenerateOptions) map[Difficulty]Pack {
+	packs := make(map[Difficulty]Pack)
+	seen := make(map[Difficulty]map[string]struct{})
+	for _, d := range Difficulties {
+		packs[d] = Pack{Name: fmt.Sprintf("%s-%d", d, opts.Seed)}
+		seen[d] = make(map[string]struct{})
+	}
+
+	full := func() bool {
+		for _, pack := range packs {
+			if len(pack.Puzzles) < opts.Count {
+				return false
+			}
+		}
+		return true
+	}
+
+	for start := uint64(0); !full() && start < MAX_CANDIDATES; start += GENERATE_BATCH_SIZE {
+		for _, graded := range gradeBatch(opts, start) {
+			for _, d := range Difficulties {
+				p, ok := graded[d]
+				pack := packs[d]
+				if !ok || len(pack.Puzzles) >= opts.Count {
+					continue
+				}
+
+				// The same puzzle only appears once per pack
+				key := fmt.Sprint(sortedCopy(p.Dice), p.Targets)
+				if _, duplicate := seen[d][key]; duplicate {
+					continue
+				}
+				seen[d][key] = struct{}{}
+
+				p.ID = fmt.Sprintf("%s-%d", d, len(pack.Puzzles)+1)
+				pack.Puzzles = append(pack.Puzzles, p)
+				packs[d] = pack
+			}
+		}
+	}
+
+	return packs
+}
+
+// gradeBatch grades candidates start to start+GENERATE_BATCH_SIZE in parallel,
+// returning them in order
+func gradeBatch(opts GenerateOptions, start uint64) []map[Difficulty]Puzzle {
+	results := make([]map[Difficulty]Puzzle, GENERATE_BATCH_SIZE)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(opts.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = gradeCandidate(opts, start+uint64(i))
+			}
+		}()
+	}
+
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// gradeCandidate rolls the dice for candidate index and makes a puzzle for
+// every difficulty that has enough targets
+func gradeCandidate(opts GenerateOptions, index uint64) map[Difficulty]Puzzle {
+	rng := rand.New(rand.NewPCG(opts.Seed, index))
+
+	dice := make([]int, opts.NumDice)
+	for i := range dice {
+		dice[i] = rng.IntN(config.DiceSides) + 1
+	}
+
+	counts := math.CountSolutions(dice, math.ALL_OPERATORS)
+	ranges := solutionRanges[opts.NumDice]
+
+	puzzles := make(map[Difficulty]Puzzle)
+	for _, d := range Difficulties {
+		var targets []int
+		for target := 1; target <= opts.MaxTarget; target++ {
+			if count := counts[target]; count >= ranges[d][0] && count <= ranges[d][1] {
+				targets = append(targets, target)
+			}
+		}
+		if len(targets) < opts.Targets {
+			continue
+		}
+
+		rng.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+		targets = targets[:opts.Targets]
+		slices.Sort(targets)
+
+		puzzles[d] = Puzzle{Dice: dice, Targets: targets}
+	}
+
+	return puzzles
+}
+
+func sortedCopy(values []int) []int {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,81 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*************************************
+* Progress
+*************************************/
+// Progress keeps the best expression found for each target, keyed by pack
+// and puzzle so packs can share ids
+type Progress map[string]map[int]string
+
+func progressKey(pack Pack, p Puzzle) string {
+	return pack.Name + "/" + p.ID
+}
+
+// LoadProgress reads saved progress, starting fresh if there is none yet
+func LoadProgress(path string) (Progress, error) {
+	progress := make(Progress)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return progress, json.Unmarshal(data, &progress)
+}
+
+func (progress Progress) Save(path string) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Record keeps exp for target if it's the first solution or shorter than the
+// best so far, reporting whether it was kept
+func (progress Progress) Record(pack Pack, p Puzzle, target int, exp string) bool {
+	key := progressKey(pack, p)
+	if progress[key] == nil {
+		progress[key] = make(map[int]string)
+	}
+
+	best, found := progress[key][target]
+	if found && len(strings.Fields(best)) <= len(strings.Fields(exp)) {
+		return false
+	}
+
+	progress[key][target] = exp
+	return true
+}
+
+func (progress Progress) Best(pack Pack, p Puzzle, target int) (string, bool) {
+	exp, found := progress[progressKey(pack, p)][target]
+	return exp, found
+}
+
+// Solved counts the puzzle's targets that have a solution
+func (progress Progress) Solved(pack Pack, p Puzzle) int {
+	solved := 0
+	for _, target := range p.Targets {
+		if _, found := progress.Best(pack, p, target); found {
+			solved++
+		}
+	}
+	return solved
+}
@@ -0,0 +1,204 @@
+package puzzle
+
+import (
+	"dicer/pkg/game"
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+/*************************************
+* Puzzles
+*************************************/
+// Constraint limits how a puzzle's expressions may be written
+type Constraint string
+
+const (
+	NO_ADDITION       Constraint = "no-addition"
+	NO_SUBTRACTION    Constraint = "no-subtraction"
+	NO_MULTIPLICATION Constraint = "no-multiplication"
+	NO_DIVISION       Constraint = "no-division"
+	USE_PARENTHESES   Constraint = "parentheses"
+)
+
+// Operators each constraint rules out
+var bannedOperators = map[Constraint]string{
+	NO_ADDITION:       "+",
+	NO_SUBTRACTION:    "-",
+	NO_MULTIPLICATION: "*",
+	NO_DIVISION:       "/",
+	USE_PARENTHESES:   "",
+}
+
+// Puzzle is a fixed set of dice with targets to hit, one expression each
+type Puzzle struct {
+	ID          string       `json:"id"`
+	Dice        []int        `json:"dice"`
+	Targets     []int        `json:"targets"`
+	Constraints []Constraint `json:"constraints,omitempty"`
+}
+
+type Pack struct {
+	Name    string   `json:"name"`
+	Puzzles []Puzzle `json:"puzzles"`
+}
+
+//go:embed packs/*.json
+var builtinPacks embed.FS
+
+// Starter is the pack that ships with the game
+func Starter() Pack {
+	data, err := builtinPacks.ReadFile("packs/starter.json")
+	if err != nil {
+		panic(err)
+	}
+
+	pack, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return pack
+}
+
+func Load(path string) (Pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pack{}, err
+	}
+	return Parse(data)
+}
+
+// Parse reads a pack and checks every puzzle in it can be solved
+func Parse(data []byte) (Pack, error) {
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return Pack{}, err
+	}
+
+	if err := pack.Validate(); err != nil {
+		return Pack{}, err
+	}
+	return pack, nil
+}
+
+func (pack Pack) Validate() error {
+	if pack.Name == "" {
+		return fmt.Errorf("pack has no name")
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range pack.Puzzles {
+		if _, duplicate := seen[p.ID]; duplicate {
+			return fmt.Errorf("puzzle %q appears more than once", p.ID)
+		}
+		seen[p.ID] = struct{}{}
+
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("puzzle %q: %w", p.ID, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the puzzle is well formed and uses the solver to make sure
+// every target can be reached within its constraints. A puzzle that needs
+// parentheses can't have a target that's reachable without them.
+func (p Puzzle) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("puzzle has no id")
+	}
+	if len(p.Dice) == 0 || len(p.Dice) > game.MAX_DICE {
+		return fmt.Errorf("needs between 1 and %d dice", game.MAX_DICE)
+	}
+	for _, value := range p.Dice {
+		if value < 1 || value > game.MAX_SIDES {
+			return fmt.Errorf("die %d must be between 1 and %d", value, game.MAX_SIDES)
+		}
+	}
+	for _, c := range p.Constraints {
+		if _, ok := bannedOperators[c]; !ok {
+			return fmt.Errorf("unknown constraint %q", c)
+		}
+	}
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("has no targets")
+	}
+
+	solutions := math.DistinctSolutions(p.Dice, p.Operators())
+	for i, target := range p.Targets {
+		if slices.Contains(p.Targets[:i], target) {
+			return fmt.Errorf("target %d appears more than once", target)
+		}
+		if len(solutions[target]) == 0 {
+			return fmt.Errorf("target %d can't be reached", target)
+		}
+		if !p.HasConstraint(USE_PARENTHESES) {
+			continue
+		}
+		if i := slices.IndexFunc(solutions[target], isParenFree); i >= 0 {
+			return fmt.Errorf("target %d can be reached without parentheses, as %s", target, solutions[target][i])
+		}
+	}
+	return nil
+}
+
+// isParenFree reports whether a canonical expression needs no parentheses
+func isParenFree(canonical string) bool {
+	return !strings.Contains(canonical, "(")
+}
+
+// Operators are the operators the puzzle's constraints allow
+func (p Puzzle) Operators() string {
+	operators := math.ALL_OPERATORS
+	for _, c := range p.Constraints {
+		operators = strings.ReplaceAll(operators, bannedOperators[c], "")
+	}
+	return operators
+}
+
+func (p Puzzle) HasConstraint(c Constraint) bool {
+	return slices.Contains(p.Constraints, c)
+}
+
+// Check validates exp the same way a game turn does, then applies the
+// puzzle's constraints. It returns the target exp hits.
+func (p Puzzle) Check(exp string) (int, error) {
+	dice := make([]models.Dice, len(p.Dice))
+	for i, value := range p.Dice {
+		dice[i] = models.Dice{Value: value, Sides: game.MAX_SIDES}
+	}
+
+	if err := game.ValidateExpression(exp, dice); err != nil {
+		return 0, err
+	}
+
+	operators := p.Operators()
+	for index, runeValue := range exp {
+		if math.IsOperator(string(runeValue)) && !strings.ContainsRune(operators, runeValue) {
+			return 0, &game.ExpressionError{Pos: index, Message: fmt.Sprintf("This puzzle doesn't allow %c", runeValue)}
+		}
+	}
+	if p.HasConstraint(USE_PARENTHESES) {
+		// Parentheses that could be left out don't count
+		canonical, err := math.Canonicalize(exp)
+		switch {
+		case err != nil:
+			return 0, &game.ExpressionError{Pos: len(exp), Message: err.Error()}
+		case !strings.Contains(exp, "("):
+			return 0, &game.ExpressionError{Pos: 0, Message: "This puzzle needs parentheses"}
+		case isParenFree(canonical):
+			return 0, &game.ExpressionError{Pos: strings.Index(exp, "("), Message: fmt.Sprintf("This puzzle needs parentheses that change the result, but this is just %s", canonical)}
+		}
+	}
+
+	result := math.EvaluateExpression(exp)
+	if !slices.Contains(p.Targets, result) {
+		return result, &game.ExpressionError{Pos: len(exp), Message: fmt.Sprintf("%d isn't one of the targets", result)}
+	}
+	return result, nil
+}
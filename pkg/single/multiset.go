@@ -0,0 +1,76 @@
+package single
+
+import "iter"
+
+// Multiset counts how many times each value has been added, such as the
+// dice that are left to use
+type Multiset[T comparable] struct {
+	counts map[T]int
+	size   int
+}
+
+func CreateMultiset[T comparable](values ...T) *Multiset[T] {
+	set := &Multiset[T]{counts: make(map[T]int)}
+	for _, val := range values {
+		set.Add(val)
+	}
+	return set
+}
+
+func (set *Multiset[T]) Add(val T) {
+	set.counts[val]++
+	set.size++
+}
+
+// Remove takes away one of val, reporting whether there was one to take
+func (set *Multiset[T]) Remove(val T) bool {
+	if set.counts[val] == 0 {
+		return false
+	}
+
+	set.counts[val]--
+	if set.counts[val] == 0 {
+		delete(set.counts, val)
+	}
+	set.size--
+	return true
+}
+
+func (set *Multiset[T]) Count(val T) int {
+	return set.counts[val]
+}
+
+func (set *Multiset[T]) Contains(val T) bool {
+	return set.counts[val] > 0
+}
+
+func (set *Multiset[T]) Len() int {
+	return set.size
+}
+
+func (set *Multiset[T]) IsEmpty() bool {
+	return set.size == 0
+}
+
+// All yields each distinct value with its count, in no particular order
+func (set *Multiset[T]) All() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for val, count := range set.counts {
+			if !yield(val, count) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice lists every value as many times as it was added, in no particular
+// order
+func (set *Multiset[T]) ToSlice() []T {
+	values := make([]T, 0, set.size)
+	for val, count := range set.All() {
+		for range count {
+			values = append(values, val)
+		}
+	}
+	return values
+}
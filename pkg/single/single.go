@@ -1,30 +1,36 @@
 package single
 
-type Node struct {
-	val  int
-	next *Node
+import "iter"
+
+type Node[T comparable] struct {
+	val  T
+	next *Node[T]
 }
 
-type LinkedList struct {
-	Head *Node
+type LinkedList[T comparable] struct {
+	Head *Node[T]
+	size int
 }
 
-func (list *LinkedList) InsertAtHead(val int) {
+func (list *LinkedList[T]) InsertAtHead(val T) {
 	tmp := list.Head
-	node := &Node{val, tmp}
+	node := &Node[T]{val, tmp}
 	list.Head = node
+	list.size++
 }
 
-func (list *LinkedList) RemoveVal(val int) {
+// Remove deletes the first node holding val, reporting whether there was one
+func (list *LinkedList[T]) Remove(val T) bool {
 	// 1. Handle empty list
 	if list.Head == nil {
-		return
+		return false
 	}
 
 	// 2. Handle deleting the Head node
 	if list.Head.val == val {
 		list.Head = list.Head.next
-		return
+		list.size--
+		return true
 	}
 
 	// 3. Search for the value in the rest of the list
@@ -32,8 +38,42 @@ func (list *LinkedList) RemoveVal(val int) {
 	for tmp.next != nil {
 		if tmp.next.val == val {
 			tmp.next = tmp.next.next
-			return
+			list.size--
+			return true
 		}
 		tmp = tmp.next
 	}
+	return false
+}
+
+func (list *LinkedList[T]) Contains(val T) bool {
+	for v := range list.All() {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func (list *LinkedList[T]) Len() int {
+	return list.size
+}
+
+// All yields each value from the head of the list on
+func (list *LinkedList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := list.Head; node != nil; node = node.next {
+			if !yield(node.val) {
+				return
+			}
+		}
+	}
+}
+
+func (list *LinkedList[T]) ToSlice() []T {
+	values := make([]T, 0, list.size)
+	for val := range list.All() {
+		values = append(values, val)
+	}
+	return values
 }
@@ -1,6 +1,9 @@
 package stack
 
-import "errors"
+import (
+	"errors"
+	"iter"
+)
 
 /************************************
 * Stack Interface
@@ -9,34 +12,49 @@ type Stack[T any] interface {
 	Push(val T) error
 	Pop()
 	Top() (T, error)
+	// Peek returns the value n below the top, so Peek(0) is the same as Top
+	Peek(n int) (T, error)
 	IsEmpty() bool
+	Len() int
+	Clear()
+	// Iter yields every value from the top of the stack down
+	Iter() iter.Seq[T]
 }
 
+var (
+	ErrStackEmpty = errors.New("stack is empty")
+	ErrStackFull  = errors.New("stack is full")
+	ErrOutOfRange = errors.New("stack isn't that deep")
+)
+
 /*********************************
 * Array Stack Implementation
 *********************************/
-const MAX_STACK_SIZE = 20
-
+// ArrayStack keeps its values in a slice that grows as needed, up to an
+// optional capacity
 type ArrayStack[T any] struct {
-	top    int
-	values [MAX_STACK_SIZE]T
+	values   []T
+	capacity int // 0 for no limit
 }
 
 func CreateArrayStack[T any]() *ArrayStack[T] {
-	var stack = &ArrayStack[T]{
-		top:    -1,
-		values: [MAX_STACK_SIZE]T{},
-	}
+	return &ArrayStack[T]{}
+}
 
-	return stack
+// CreateArrayStackWithCapacity makes a stack that holds at most capacity
+// values, refusing any more with ErrStackFull
+func CreateArrayStackWithCapacity[T any](capacity int) *ArrayStack[T] {
+	return &ArrayStack[T]{
+		values:   make([]T, 0, capacity),
+		capacity: capacity,
+	}
 }
 
 func (stack *ArrayStack[T]) Push(val T) error {
-	if stack.top == MAX_STACK_SIZE-1 {
-		return errors.New("stack is full")
+	if stack.capacity > 0 && len(stack.values) == stack.capacity {
+		return ErrStackFull
 	}
-	stack.top++
-	stack.values[stack.top] = val
+	stack.values = append(stack.values, val)
 
 	return nil
 }
@@ -45,19 +63,57 @@ func (stack *ArrayStack[T]) Pop() {
 	if stack.IsEmpty() {
 		return
 	}
-	stack.top--
+	// Let go of the popped value so it can be garbage collected
+	var zero T
+	stack.values[len(stack.values)-1] = zero
+	stack.values = stack.values[:len(stack.values)-1]
 }
 
 func (stack *ArrayStack[T]) Top() (T, error) {
+	return stack.Peek(0)
+}
+
+func (stack *ArrayStack[T]) Peek(n int) (T, error) {
 	if stack.IsEmpty() {
 		var zero T
-		return zero, errors.New("stack is empty")
+		return zero, ErrStackEmpty
+	}
+	if n < 0 || n >= len(stack.values) {
+		var zero T
+		return zero, ErrOutOfRange
 	}
-	return stack.values[stack.top], nil
+	return stack.values[len(stack.values)-1-n], nil
 }
 
 func (stack *ArrayStack[T]) IsEmpty() bool {
-	return stack.top == -1
+	return len(stack.values) == 0
+}
+
+func (stack *ArrayStack[T]) Len() int {
+	return len(stack.values)
+}
+
+func (stack *ArrayStack[T]) Clear() {
+	clear(stack.values)
+	stack.values = stack.values[:0]
+}
+
+func (stack *ArrayStack[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(stack.values) - 1; i >= 0; i-- {
+			if !yield(stack.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Clone copies the stack, so pushing to one doesn't change the other
+func (stack *ArrayStack[T]) Clone() *ArrayStack[T] {
+	values := make([]T, len(stack.values), max(len(stack.values), stack.capacity))
+	copy(values, stack.values)
+
+	return &ArrayStack[T]{values: values, capacity: stack.capacity}
 }
 
 /*********************************
@@ -70,12 +126,14 @@ type StackNode[T any] struct {
 
 type StackList[T any] struct {
 	Head *StackNode[T]
+	size int
 }
 
 func (list *StackList[T]) Push(val T) error {
 	new := &StackNode[T]{Val: val}
 	new.Next = list.Head
 	list.Head = new
+	list.size++
 
 	return nil
 }
@@ -88,17 +146,65 @@ func (list *StackList[T]) Pop() {
 	newHead := currHead.Next
 
 	list.Head = newHead
+	list.size--
 }
 
 func (list *StackList[T]) Top() (T, error) {
+	return list.Peek(0)
+}
+
+func (list *StackList[T]) Peek(n int) (T, error) {
 	if list.Head == nil {
 		var zero T
-		return zero, errors.New("stack is empty")
+		return zero, ErrStackEmpty
+	}
+	if n < 0 {
+		var zero T
+		return zero, ErrOutOfRange
 	}
 
-	return list.Head.Val, nil
+	node := list.Head
+	for range n {
+		node = node.Next
+		if node == nil {
+			var zero T
+			return zero, ErrOutOfRange
+		}
+	}
+	return node.Val, nil
 }
 
 func (list *StackList[T]) IsEmpty() bool {
 	return list.Head == nil
 }
+
+func (list *StackList[T]) Len() int {
+	return list.size
+}
+
+func (list *StackList[T]) Clear() {
+	list.Head = nil
+	list.size = 0
+}
+
+func (list *StackList[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := list.Head; node != nil; node = node.Next {
+			if !yield(node.Val) {
+				return
+			}
+		}
+	}
+}
+
+// Clone copies the list, so pushing to one doesn't change the other
+func (list *StackList[T]) Clone() *StackList[T] {
+	clone := &StackList[T]{size: list.size}
+
+	tail := &clone.Head
+	for node := list.Head; node != nil; node = node.Next {
+		*tail = &StackNode[T]{Val: node.Val}
+		tail = &(*tail).Next
+	}
+	return clone
+}
@@ -0,0 +1,266 @@
+package stack
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// implementations are run through the same cases under the Stack interface
+var implementations = []struct {
+	name   string
+	create func() Stack[int]
+}{
+	{"ArrayStack", func() Stack[int] { return CreateArrayStack[int]() }},
+	{"StackList", func() Stack[int] { return &StackList[int]{} }},
+}
+
+func pushAll(t testing.TB, stack Stack[int], values []int) {
+	t.Helper()
+	for _, val := range values {
+		if err := stack.Push(val); err != nil {
+			t.Fatalf("Push(%d) returned %v", val, err)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushes  []int
+		pops    int
+		wantTop int
+		wantErr error
+		wantLen int
+		want    []int // From the top down
+	}{
+		{name: "empty", wantErr: ErrStackEmpty},
+		{name: "one value", pushes: []int{7}, wantTop: 7, wantLen: 1, want: []int{7}},
+		{name: "last in first out", pushes: []int{1, 2, 3}, wantTop: 3, wantLen: 3, want: []int{3, 2, 1}},
+		{name: "pop", pushes: []int{1, 2, 3}, pops: 1, wantTop: 2, wantLen: 2, want: []int{2, 1}},
+		{name: "pop everything", pushes: []int{1, 2}, pops: 2, wantErr: ErrStackEmpty},
+		{name: "pop past empty", pushes: []int{1}, pops: 3, wantErr: ErrStackEmpty},
+		{name: "zero values", pushes: []int{0, 0}, wantTop: 0, wantLen: 2, want: []int{0, 0}},
+		{name: "past the old limit of 20", pushes: slices.Repeat([]int{4}, 50), pops: 5, wantTop: 4, wantLen: 45, want: slices.Repeat([]int{4}, 45)},
+	}
+
+	for _, impl := range implementations {
+		for _, tt := range tests {
+			t.Run(impl.name+"/"+tt.name, func(t *testing.T) {
+				stack := impl.create()
+				pushAll(t, stack, tt.pushes)
+				for range tt.pops {
+					stack.Pop()
+				}
+
+				top, err := stack.Top()
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Top() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && top != tt.wantTop {
+					t.Errorf("Top() = %d, want %d", top, tt.wantTop)
+				}
+				if got := stack.Len(); got != tt.wantLen {
+					t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+				}
+				if got := stack.IsEmpty(); got != (tt.wantLen == 0) {
+					t.Errorf("IsEmpty() = %v, want %v", got, tt.wantLen == 0)
+				}
+				if got := slices.Collect(stack.Iter()); !slices.Equal(got, tt.want) {
+					t.Errorf("Iter() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestPeek(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushes  []int
+		n       int
+		want    int
+		wantErr error
+	}{
+		{name: "empty", n: 0, wantErr: ErrStackEmpty},
+		{name: "top", pushes: []int{1, 2, 3}, n: 0, want: 3},
+		{name: "middle", pushes: []int{1, 2, 3}, n: 1, want: 2},
+		{name: "bottom", pushes: []int{1, 2, 3}, n: 2, want: 1},
+		{name: "too deep", pushes: []int{1, 2, 3}, n: 3, wantErr: ErrOutOfRange},
+		{name: "negative", pushes: []int{1, 2, 3}, n: -1, wantErr: ErrOutOfRange},
+	}
+
+	for _, impl := range implementations {
+		for _, tt := range tests {
+			t.Run(impl.name+"/"+tt.name, func(t *testing.T) {
+				stack := impl.create()
+				pushAll(t, stack, tt.pushes)
+
+				got, err := stack.Peek(tt.n)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Peek(%d) error = %v, want %v", tt.n, err, tt.wantErr)
+				}
+				if err == nil && got != tt.want {
+					t.Errorf("Peek(%d) = %d, want %d", tt.n, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			stack := impl.create()
+			pushAll(t, stack, []int{1, 2, 3})
+			stack.Clear()
+
+			if !stack.IsEmpty() || stack.Len() != 0 {
+				t.Fatalf("after Clear() Len() = %d, want 0", stack.Len())
+			}
+			pushAll(t, stack, []int{9})
+			if top, _ := stack.Top(); top != 9 || stack.Len() != 1 {
+				t.Errorf("after Clear() and Push(9) Top() = %d and Len() = %d", top, stack.Len())
+			}
+		})
+	}
+}
+
+func TestIterStopsEarly(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			stack := impl.create()
+			pushAll(t, stack, []int{1, 2, 3, 4})
+
+			var got []int
+			for val := range stack.Iter() {
+				got = append(got, val)
+				if len(got) == 2 {
+					break
+				}
+			}
+			if !slices.Equal(got, []int{4, 3}) {
+				t.Errorf("Iter() with break = %v, want [4 3]", got)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	clones := []struct {
+		name  string
+		clone func(values []int) (original Stack[int], clone Stack[int])
+	}{
+		{"ArrayStack", func(values []int) (Stack[int], Stack[int]) {
+			stack := CreateArrayStack[int]()
+			pushAll(t, stack, values)
+			return stack, stack.Clone()
+		}},
+		{"StackList", func(values []int) (Stack[int], Stack[int]) {
+			stack := &StackList[int]{}
+			pushAll(t, stack, values)
+			return stack, stack.Clone()
+		}},
+	}
+
+	for _, c := range clones {
+		t.Run(c.name, func(t *testing.T) {
+			original, clone := c.clone([]int{1, 2, 3})
+			clone.Pop()
+			pushAll(t, clone, []int{8, 9})
+
+			if got := slices.Collect(original.Iter()); !slices.Equal(got, []int{3, 2, 1}) {
+				t.Errorf("original after changing the clone = %v, want [3 2 1]", got)
+			}
+			if got := slices.Collect(clone.Iter()); !slices.Equal(got, []int{9, 8, 2, 1}) {
+				t.Errorf("clone = %v, want [9 8 2 1]", got)
+			}
+			if clone.Len() != 4 {
+				t.Errorf("clone Len() = %d, want 4", clone.Len())
+			}
+		})
+	}
+}
+
+func TestArrayStackCapacity(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		pushes   int
+		wantErr  error
+	}{
+		{name: "under", capacity: 3, pushes: 2},
+		{name: "at", capacity: 3, pushes: 3},
+		{name: "over", capacity: 3, pushes: 4, wantErr: ErrStackFull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := CreateArrayStackWithCapacity[int](tt.capacity)
+
+			var err error
+			for i := range tt.pushes {
+				err = stack.Push(i)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("last Push() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := stack.Len(); got != min(tt.pushes, tt.capacity) {
+				t.Errorf("Len() = %d, want %d", got, min(tt.pushes, tt.capacity))
+			}
+
+			// Popping makes room again, and a clone keeps the same limit
+			stack.Pop()
+			if err := stack.Push(1); err != nil {
+				t.Errorf("Push() after Pop() returned %v", err)
+			}
+			if err := stack.Clone().Push(1); tt.pushes >= tt.capacity && !errors.Is(err, ErrStackFull) {
+				t.Errorf("Push() on a full clone returned %v, want %v", err, ErrStackFull)
+			}
+		})
+	}
+}
+
+var _ Stack[int] = (*ArrayStack[int])(nil)
+var _ Stack[int] = (*StackList[int])(nil)
+
+func BenchmarkPushPop(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			stack := impl.create()
+			for b.Loop() {
+				for i := range 64 {
+					stack.Push(i)
+				}
+				for range 64 {
+					stack.Pop()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPeek(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			stack := impl.create()
+			pushAll(b, stack, slices.Repeat([]int{1}, 64))
+			for b.Loop() {
+				stack.Peek(32)
+			}
+		})
+	}
+}
+
+func BenchmarkIter(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			stack := impl.create()
+			pushAll(b, stack, slices.Repeat([]int{1}, 64))
+			for b.Loop() {
+				for range stack.Iter() {
+				}
+			}
+		})
+	}
+}
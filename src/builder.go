@@ -0,0 +1,114 @@
+package main
+
+import (
+	"dicer/pkg/math"
+	"fmt"
+	"strings"
+)
+
+/*************************************
+* Expression Builder
+*************************************/
+// expressionBuilder assembles an expression one token at a time and only
+// accepts tokens that keep it structurally valid.
+type expressionBuilder struct {
+	tokens []builderToken
+	used   map[int]struct{}
+	depth  int
+}
+
+type builderToken struct {
+	value string
+	die   int // Index of the die the token came from, -1 for operators and parentheses
+}
+
+func newExpressionBuilder() *expressionBuilder {
+	return &expressionBuilder{used: make(map[int]struct{})}
+}
+
+// expectsOperand is true when the next token has to be a die or (
+func (b *expressionBuilder) expectsOperand() bool {
+	if len(b.tokens) == 0 {
+		return true
+	}
+
+	last := b.tokens[len(b.tokens)-1].value
+	return last == "(" || math.IsOperator(last)
+}
+
+func (b *expressionBuilder) hasUnusedDice(numDice int) bool {
+	return len(b.used) < numDice
+}
+
+func (b *expressionBuilder) isUsed(index int) bool {
+	_, ok := b.used[index]
+	return ok
+}
+
+func (b *expressionBuilder) addDie(index int, value int) bool {
+	if !b.expectsOperand() || b.isUsed(index) {
+		return false
+	}
+
+	b.tokens = append(b.tokens, builderToken{fmt.Sprintf("%d", value), index})
+	b.used[index] = struct{}{}
+	return true
+}
+
+// addSymbol appends an operator or parenthesis, rejecting anything that
+// would leave the expression impossible to finish.
+func (b *expressionBuilder) addSymbol(symbol string, numDice int) bool {
+	switch {
+	case symbol == "(":
+		if !b.expectsOperand() || !b.hasUnusedDice(numDice) {
+			return false
+		}
+		b.depth++
+	case symbol == ")":
+		if b.expectsOperand() || b.depth == 0 {
+			return false
+		}
+		b.depth--
+	case math.IsOperator(symbol):
+		if b.expectsOperand() || !b.hasUnusedDice(numDice) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	b.tokens = append(b.tokens, builderToken{symbol, -1})
+	return true
+}
+
+func (b *expressionBuilder) undo() {
+	if len(b.tokens) == 0 {
+		return
+	}
+
+	last := b.tokens[len(b.tokens)-1]
+	b.tokens = b.tokens[:len(b.tokens)-1]
+
+	switch last.value {
+	case "(":
+		b.depth--
+	case ")":
+		b.depth++
+	}
+	if last.die != -1 {
+		delete(b.used, last.die)
+	}
+}
+
+func (b *expressionBuilder) isComplete(numDice int) bool {
+	return !b.hasUnusedDice(numDice) && b.depth == 0 && !b.expectsOperand()
+}
+
+func (b *expressionBuilder) String() string {
+	values := make([]string, len(b.tokens))
+	for i, token := range b.tokens {
+		values[i] = token.value
+	}
+
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"dicer/pkg/game"
+	"fmt"
+	"strings"
+)
+
+/*************************************
+* Campaign
+*************************************/
+// advanceLevel moves a campaign on to its next level, keeping the player's
+// lives and items
+func (m *model) advanceLevel() {
+	if err := m.campaign.NextLevel(); err != nil {
+		m.debug = err.Error()
+		return
+	}
+
+	m.game = m.campaign.Game
+	m.textInput.Reset()
+	m.builder = newExpressionBuilder()
+	m.selected = make(map[int]struct{})
+	m.cursor = 0
+	m.itemCursor = 0
+	m.choosingValue = false
+	m.debug = ""
+	m.syncChoices()
+}
+
+func (m model) levelText() string {
+	return fmt.Sprintf("Level: %d/%d", m.campaign.Level+1, len(m.campaign.Levels))
+}
+
+// levelIntro introduces the level at the start of its first turn
+func (m model) levelIntro() string {
+	level := m.campaign.CurrentLevel()
+	return fmt.Sprintf("Level %d: %s. %s.", m.campaign.Level+1, level.Name, level.Description)
+}
+
+// campaignOverMessage is shown when a campaign level ends, won or lost
+func (m model) campaignOverMessage() string {
+	switch {
+	case m.campaign.Won():
+		return "You cleared every level! What a run.\n\n" + formatRunSummary(m.campaign.Summary())
+	case m.campaign.LevelCleared():
+		next := m.campaign.Levels[m.campaign.Level+1]
+		return fmt.Sprintf("Level cleared! You get a life back.\nPress %s for level %d: %s.",
+			m.keys.Confirm.Help().Key, m.campaign.Level+2, next.Name)
+	}
+	return fmt.Sprintf("Your run ends on level %d.\n\n%s", m.campaign.Level+1, formatRunSummary(m.campaign.Summary()))
+}
+
+func formatRunSummary(summary game.RunSummary) string {
+	lines := []string{fmt.Sprintf("Run %d: %d of %d levels cleared", summary.Seed, summary.LevelsCleared, len(game.CampaignLevels))}
+	for i, level := range summary.Levels {
+		lines = append(lines, fmt.Sprintf("  %d. %-12s %3d turns  %3d hits  %d lives lost", i+1, level.Name, level.Turns, level.Hits, level.LivesLost))
+	}
+	lines = append(lines, fmt.Sprintf("Total: %d turns, %d hits, %d lives lost, %d items used", summary.Turns, summary.Hits, summary.LivesLost, summary.ItemsUsed))
+	lines = append(lines, fmt.Sprintf("Score: %d", summary.Score))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"dicer/pkg/game"
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*************************************
+* Debug Panel
+*************************************/
+// How many of the latest messages the debug panel lists
+const DEBUG_MESSAGES = 6
+
+// renderTiming is shared between copies of the model, since View can't
+// change the model it renders
+type renderTiming struct {
+	last  time.Duration
+	total time.Duration
+	count int
+}
+
+func (r *renderTiming) record(took time.Duration) {
+	if r == nil {
+		return
+	}
+	r.last = took
+	r.total += took
+	r.count++
+}
+
+// recordMsg keeps the latest messages for the debug panel
+func (m *model) recordMsg(msg tea.Msg) {
+	text := fmt.Sprintf("%T %v", msg, msg)
+	m.recentMsgs = append(slices.Clone(m.recentMsgs[max(len(m.recentMsgs)-DEBUG_MESSAGES+1, 0):]), text)
+}
+
+// overlayDebugPanel draws the debug panel over the bottom of ui
+func (m model) overlayDebugPanel(ui string) string {
+	panel := m.getDebugPanel(m.width)
+
+	lines := strings.Split(ui, "\n")
+	panelLines := strings.Split(panel, "\n")
+	start := max(len(lines)-len(panelLines), 0)
+	return strings.Join(append(lines[:start], panelLines...), "\n")
+}
+
+func (m model) getDebugPanel(width int) string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Error).
+		Width(max(width-2, 0)).
+		Padding(0, 1)
+	labelStyle := lipgloss.NewStyle().Foreground(m.theme.Instructions)
+
+	// Truncate long values so each row stays on one line
+	inner := max(width-style.GetHorizontalFrameSize()-10, 10)
+	row := func(label string, value string) string {
+		if runes := []rune(value); len(runes) > inner {
+			value = string(runes[:inner-3]) + "..."
+		}
+		return labelStyle.Render(fmt.Sprintf("%-9s", label)) + " " + value
+	}
+
+	var phases []string
+	for phase := range m.game.Turn.Stack.Iter() {
+		phases = append(phases, phase.String())
+	}
+
+	rows := []string{
+		row("Stack", strings.Join(phases, " > ")),
+		row("Source", describeSource(m.game.Source)),
+		row("Dice", fmt.Sprintf("%+v", m.game.Turn.Dice)),
+		row("Postfix", debugPostfix(m.currentExpression())),
+		row("Render", m.renders.describe()),
+	}
+	for i, msg := range slices.Backward(m.recentMsgs) {
+		label := ""
+		if i == len(m.recentMsgs)-1 {
+			label = "Messages"
+		}
+		rows = append(rows, row(label, msg))
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// currentExpression is what's being typed or built, or else what was last
+// submitted
+func (m model) currentExpression() string {
+	switch phase, _ := m.getCurrentState(); {
+	case phase == models.GS_ExpressionPhase && m.building:
+		return m.builder.String()
+	case phase == models.GS_ExpressionPhase && m.textInput.Value() != "":
+		return m.textInput.Value()
+	}
+	return m.game.Turn.Expression
+}
+
+// debugPostfix converts each part of a combo on its own
+func debugPostfix(exp string) string {
+	if strings.TrimSpace(exp) == "" {
+		return "(none)"
+	}
+
+	var parts []string
+	for _, part := range game.SplitCombo(exp) {
+		parts = append(parts, strings.TrimSpace(math.InfixToPostfix(part.Expression)))
+	}
+	return strings.Join(parts, " , ")
+}
+
+func describeSource(source models.DiceSource) string {
+	switch source := source.(type) {
+	case *models.SeededSource:
+		return fmt.Sprintf("seed %d, %d rolls", source.Seed, source.Rolls())
+	case *models.ScriptedSource:
+		return fmt.Sprintf("scripted, %d rolls", source.Rolls())
+	}
+	return fmt.Sprintf("%T, no seed", source)
+}
+
+func (r *renderTiming) describe() string {
+	if r == nil || r.count == 0 {
+		return "(none yet)"
+	}
+	average := r.total / time.Duration(r.count)
+	return fmt.Sprintf("last %s, average %s over %d renders", r.last.Round(time.Microsecond), average.Round(time.Microsecond), r.count)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"dicer/pkg/models"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*************************************
+* Dice Faces
+*************************************/
+// Pip layouts for a d6, three rows of three cells
+var pipFaces = map[int][3]string{
+	1: {"   ", " ● ", "   "},
+	2: {"●  ", "   ", "  ●"},
+	3: {"●  ", " ● ", "  ●"},
+	4: {"● ●", "   ", "● ●"},
+	5: {"● ●", " ● ", "● ●"},
+	6: {"● ●", "● ●", "● ●"},
+}
+
+// dieFace draws value as pips on a d6, falling back to the digit for bigger
+// dice and in accessible mode.
+func (opts options) dieFace(value int, sides int) string {
+	face, ok := pipFaces[value]
+	if !ok || sides > 6 || opts.accessible {
+		return fmt.Sprintf("\n%d\n", value)
+	}
+
+	return strings.Join(face[:], "\n")
+}
+
+/*************************************
+* Roll Animation
+*************************************/
+const ROLL_FRAMES = 8
+const ROLL_FRAME_DURATION = 60 * time.Millisecond
+
+type rollTickMsg struct {
+	id int
+}
+
+// rollAnimation tracks which dice are tumbling and how many frames are left
+type rollAnimation struct {
+	id     int
+	frames int
+	dice   map[int]struct{}
+}
+
+func (a rollAnimation) isRolling(index int) bool {
+	_, ok := a.dice[index]
+	return a.frames > 0 && ok
+}
+
+// startRollAnimation tumbles the given dice for a few frames before they
+// settle on the values already rolled.
+func (m *model) startRollAnimation(indices map[int]struct{}) tea.Cmd {
+	if !m.animate || len(indices) == 0 {
+		return nil
+	}
+
+	dice := make(map[int]struct{}, len(indices))
+	for i := range indices {
+		dice[i] = struct{}{}
+	}
+
+	m.rolling = rollAnimation{
+		id:     m.rolling.id + 1,
+		frames: ROLL_FRAMES,
+		dice:   dice,
+	}
+
+	return rollTick(m.rolling.id)
+}
+
+func (m *model) skipRollAnimation() {
+	m.rolling.frames = 0
+}
+
+func (m *model) handleRollTick(msg rollTickMsg) tea.Cmd {
+	// Ticks from an animation that was skipped or replaced are dropped
+	if msg.id != m.rolling.id || m.rolling.frames == 0 {
+		return nil
+	}
+
+	m.rolling.frames--
+	if m.rolling.frames == 0 {
+		return nil
+	}
+	return rollTick(m.rolling.id)
+}
+
+func rollTick(id int) tea.Cmd {
+	return tea.Tick(ROLL_FRAME_DURATION, func(time.Time) tea.Msg {
+		return rollTickMsg{id}
+	})
+}
+
+// displayValue is the face to draw for a die, random while it's tumbling
+func (m model) displayValue(index int, die models.Dice) int {
+	if m.rolling.isRolling(index) && die.Sides > 0 {
+		return rand.IntN(die.Sides) + 1
+	}
+	return die.Value
+}
+
+func allDice(dice []models.Dice) map[int]struct{} {
+	indices := make(map[int]struct{}, len(dice))
+	for i := range dice {
+		indices[i] = struct{}{}
+	}
+	return indices
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"dicer/pkg/logger"
+	"dicer/pkg/puzzle"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*************************************
+* Puzzle Generator
+*************************************/
+// runGenerate writes a puzzle pack for each difficulty into a directory
+func runGenerate(args []string) error {
+	opts := puzzle.DefaultGenerateOptions()
+
+	flags := flag.NewFlagSet("dicer generate", flag.ContinueOnError)
+	flags.Uint64Var(&opts.Seed, "seed", opts.Seed, "seed for the dice, the same seed makes the same packs")
+	flags.IntVar(&opts.Count, "count", opts.Count, "puzzles per difficulty")
+	flags.IntVar(&opts.NumDice, "dice", opts.NumDice, "dice per puzzle")
+	flags.IntVar(&opts.Targets, "targets", opts.Targets, "targets per puzzle")
+	flags.IntVar(&opts.MaxTarget, "max-target", opts.MaxTarget, "highest target to choose from")
+	flags.IntVar(&opts.Workers, "workers", opts.Workers, "puzzles to grade at once")
+	out := flags.String("out", ".", "directory to write the packs to")
+	logOptions := addLogFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logOpts, err := logOptions(os.Stderr)
+	if err != nil {
+		return err
+	}
+	closeLog, err := logger.Setup(logOpts)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	packs := puzzle.Generate(opts)
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+
+	for _, d := range puzzle.Difficulties {
+		pack := packs[d]
+		if len(pack.Puzzles) < opts.Count {
+			fmt.Printf("Only found %d %s puzzles\n", len(pack.Puzzles), d)
+		}
+
+		data, err := json.MarshalIndent(pack, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(*out, pack.Name+".json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d puzzles to %s\n", len(pack.Puzzles), path)
+	}
+
+	fmt.Printf("Done in %s\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"dicer/pkg/models"
+	"fmt"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*************************************
+* Items
+*************************************/
+// selectedItem is the effect of the item under the item cursor
+func (m *model) selectedItem() (models.Effect, bool) {
+	if m.itemCursor >= len(m.game.Player.Items) {
+		return nil, false
+	}
+	return models.LookupItem(m.game.Player.Items[m.itemCursor])
+}
+
+// On [ item ] press, moving to the next item in the inventory
+func (m *model) handleItemKey(state models.TurnPhase) {
+	if state != models.GS_RollPhase || len(m.game.Player.Items) == 0 {
+		return
+	}
+	m.itemCursor = (m.itemCursor + 1) % len(m.game.Player.Items)
+}
+
+// On [ use item ] press. Items that need a value wait for the next key.
+func (m *model) handleUseItemKey(state models.TurnPhase) {
+	if state != models.GS_RollPhase {
+		return
+	}
+
+	effect, ok := m.selectedItem()
+	if !ok {
+		m.debug = "You don't have any items"
+		return
+	}
+
+	switch effect.Needs() {
+	case models.NEEDS_NOTHING:
+		m.useItem(models.ItemUse{})
+	case models.NEEDS_DIE:
+		m.useItem(models.ItemUse{Die: m.cursor})
+	case models.NEEDS_VALUE:
+		m.choosingValue = true
+	}
+}
+
+// handleItemValueKey finishes using a Nudge or Wild with the key pressed
+// after it. Any other key puts the item away unused.
+func (m *model) handleItemValueKey(msg tea.KeyMsg) {
+	m.choosingValue = false
+
+	effect, ok := m.selectedItem()
+	if !ok {
+		return
+	}
+
+	value, ok := itemValue(effect.Kind(), msg.String())
+	if !ok {
+		m.itemNotice = fmt.Sprintf("Put the %s away.", effect.Name())
+		return
+	}
+	m.useItem(models.ItemUse{Die: m.cursor, Value: value})
+}
+
+// itemValue reads + or - for a Nudge, or a digit for a Wild
+func itemValue(kind models.ItemKind, pressed string) (int, bool) {
+	switch kind {
+	case models.ITEM_NUDGE:
+		switch pressed {
+		case "+":
+			return 1, true
+		case "-":
+			return -1, true
+		}
+	case models.ITEM_WILD:
+		if value, err := strconv.Atoi(pressed); err == nil {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+func (m *model) useItem(use models.ItemUse) {
+	effect, _ := m.selectedItem()
+	if err := m.game.UseItem(m.itemCursor, use); err != nil {
+		m.debug = err.Error()
+		return
+	}
+
+	m.debug = ""
+	m.itemNotice = fmt.Sprintf("Used %s.", effect.Name())
+	m.itemCursor = min(m.itemCursor, max(len(m.game.Player.Items)-1, 0))
+	m.syncChoices()
+}
+
+// itemPrompt asks for the value a Nudge or Wild needs
+func (m model) itemPrompt() string {
+	effect, ok := m.selectedItem()
+	if !ok {
+		return ""
+	}
+
+	die := m.cursor + 1
+	if effect.Kind() == models.ITEM_NUDGE {
+		return fmt.Sprintf("Press + or - to nudge die %d.", die)
+	}
+	return fmt.Sprintf("Press 1-%d to set die %d.", m.game.Turn.Dice[m.cursor].Sides, die)
+}
+
+// syncChoices keeps a re-roll choice under every die, as an Extra Die adds
+// one for a turn
+func (m *model) syncChoices() {
+	m.choices = make([]string, len(m.game.Turn.Dice))
+	if len(m.choices) == 0 {
+		m.choices = make([]string, m.game.Rules.NumDice)
+	}
+	m.cursor = min(m.cursor, len(m.choices)-1)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"dicer/pkg/models"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+/*************************************
+* Key Bindings
+*************************************/
+type keyMap struct {
+	Quit        key.Binding
+	ForceQuit   key.Binding
+	Pause       key.Binding
+	Roll        key.Binding
+	Left        key.Binding
+	Right       key.Binding
+	Select      key.Binding // Toggle a die, use a die or continue
+	Confirm     key.Binding // Re-roll, submit or restart
+	HistoryPrev key.Binding
+	HistoryNext key.Binding
+	Builder     key.Binding
+	Undo        key.Binding
+	Operator    key.Binding
+	Die         key.Binding // Pick a die directly by its position
+	Item        key.Binding // Move to the next item
+	UseItem     key.Binding
+	Challenge   key.Binding // Claim the roll can't reach anything
+	Debug       key.Binding // Show the debug panel
+	Up          key.Binding // Menu navigation
+	Down        key.Binding
+	Yes         key.Binding
+	No          key.Binding
+}
+
+const KEY_PRESET_DEFAULT = "default"
+
+// typingActions can be used while the expression is being typed, when every
+// character goes to the text input instead of the key bindings
+var typingActions = []string{"pause", "confirm", "history_prev", "history_next", "builder", "challenge", "debug"}
+
+// keyPresets adjust the default bindings for different styles of play
+var keyPresets = map[string]func(*keyMap){
+	KEY_PRESET_DEFAULT: func(*keyMap) {},
+	"arrows": func(k *keyMap) {
+		k.Left = newBinding("left")
+		k.Right = newBinding("right")
+	},
+	"vim": func(k *keyMap) {
+		k.Left = newBinding("h")
+		k.Right = newBinding("l")
+		k.HistoryPrev = newBinding("ctrl+p", "up")
+		k.HistoryNext = newBinding("ctrl+n", "down")
+	},
+	"numpad": func(k *keyMap) {
+		k.Die = newBinding("1", "2", "3", "4", "5", "6", "7", "8", "9")
+		k.Die.SetHelp("1-9", "pick die")
+	},
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:        newBinding("q"),
+		ForceQuit:   newBinding("ctrl+c"),
+		Pause:       newBinding("esc"),
+		Roll:        newBinding("r"),
+		Left:        newBinding("left", "h"),
+		Right:       newBinding("right", "l"),
+		Select:      newBinding(" "),
+		Confirm:     newBinding("enter"),
+		HistoryPrev: newBinding("up"),
+		HistoryNext: newBinding("down"),
+		Builder:     newBinding("tab"),
+		Undo:        newBinding("backspace"),
+		Operator:    newBinding("+", "-", "*", "/", "(", ")"),
+		Die:         key.NewBinding(key.WithDisabled()),
+		Item:        newBinding("i"),
+		UseItem:     newBinding("u"),
+		Challenge:   newBinding("ctrl+x"),
+		Debug:       newBinding("f12", "~"),
+		Up:          newBinding("up", "k"),
+		Down:        newBinding("down", "j"),
+		Yes:         newBinding("y"),
+		No:          newBinding("n"),
+	}
+}
+
+// newKeyMap applies a preset and then any per-action overrides from the
+// config file, keyed by action name such as "roll" or "history_prev".
+func newKeyMap(preset string, overrides map[string][]string) (keyMap, error) {
+	keys := defaultKeyMap()
+
+	if preset == "" {
+		preset = KEY_PRESET_DEFAULT
+	}
+	applyPreset, ok := keyPresets[preset]
+	if !ok {
+		return keyMap{}, fmt.Errorf("unknown key preset %q, expected one of: %s", preset, strings.Join(keyPresetNames(), ", "))
+	}
+	applyPreset(&keys)
+
+	actions := map[string]*key.Binding{
+		"quit":         &keys.Quit,
+		"pause":        &keys.Pause,
+		"roll":         &keys.Roll,
+		"left":         &keys.Left,
+		"right":        &keys.Right,
+		"select":       &keys.Select,
+		"confirm":      &keys.Confirm,
+		"history_prev": &keys.HistoryPrev,
+		"history_next": &keys.HistoryNext,
+		"builder":      &keys.Builder,
+		"undo":         &keys.Undo,
+		"operator":     &keys.Operator,
+		"die":          &keys.Die,
+		"item":         &keys.Item,
+		"use_item":     &keys.UseItem,
+		"challenge":    &keys.Challenge,
+		"debug":        &keys.Debug,
+	}
+
+	for action, values := range overrides {
+		binding, ok := actions[action]
+		if !ok {
+			return keyMap{}, fmt.Errorf("unknown key action %q", action)
+		}
+		if slices.Contains(typingActions, action) {
+			for _, k := range values {
+				if isTypedKey(k) {
+					return keyMap{}, fmt.Errorf("key %q can't be bound to %q, it would be typed into the expression instead", k, action)
+				}
+			}
+		}
+		*binding = newBinding(values...)
+	}
+
+	return keys, nil
+}
+
+func newBinding(keys ...string) key.Binding {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = k
+		if k == " " {
+			labels[i] = "space"
+		}
+	}
+
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(labels, "/"), ""),
+	)
+}
+
+// isTypedKey reports whether the text input takes k while an expression is
+// being typed
+func isTypedKey(k string) bool {
+	k = strings.TrimPrefix(k, "alt+")
+	return utf8.RuneCountInString(k) == 1
+}
+
+// describe returns a copy of binding with the help text for one phase
+func describe(binding key.Binding, desc string) key.Binding {
+	binding.SetHelp(binding.Help().Key, desc)
+	return binding
+}
+
+// dieIndex maps a Die binding key press to the position of the die it picks
+func dieIndex(pressed string) (int, bool) {
+	num, err := strconv.Atoi(pressed)
+	if err != nil || num < 1 {
+		return 0, false
+	}
+	return num - 1, true
+}
+
+// phaseBindings are the keys that do something in the given phase, in the
+// order they're shown in the help footer.
+func (k keyMap) phaseBindings(state models.TurnPhase, building bool) []key.Binding {
+	switch state {
+	case models.GS_TurnStart:
+		return []key.Binding{describe(k.Roll, "roll the dice"), describe(k.Pause, "menu"), describe(k.Quit, "quit")}
+	case models.GS_RollPhase:
+		return []key.Binding{
+			describe(k.Left, "left"),
+			describe(k.Right, "right"),
+			describe(k.Select, "toggle"),
+			describe(k.Die, "toggle die"),
+			describe(k.Confirm, "re-roll"),
+			describe(k.Pause, "menu"),
+		}
+	case models.GS_ExpressionPhase:
+		if building {
+			return []key.Binding{
+				describe(k.Left, "left"),
+				describe(k.Right, "right"),
+				describe(k.Select, "use die"),
+				describe(k.Die, "use die"),
+				describe(k.Operator, "add"),
+				describe(k.Undo, "undo"),
+				describe(k.Builder, "type"),
+				describe(k.Confirm, "submit"),
+				describe(k.Challenge, "challenge"),
+				describe(k.Pause, "menu"),
+			}
+		}
+		return []key.Binding{
+			describe(k.Confirm, "submit"),
+			describe(k.HistoryPrev, "earlier"),
+			describe(k.HistoryNext, "later"),
+			describe(k.Builder, "pick dice instead"),
+			describe(k.Challenge, "challenge"),
+			describe(k.Pause, "menu"),
+		}
+	case models.GS_ResultsPhase:
+		return []key.Binding{describe(k.Select, "continue"), describe(k.Pause, "menu"), describe(k.Quit, "quit")}
+	case models.GS_GameOver:
+		return []key.Binding{describe(k.Confirm, "restart"), describe(k.Pause, "menu"), describe(k.Quit, "quit")}
+	}
+
+	return nil
+}
+
+func keyPresetNames() []string {
+	names := make([]string, 0, len(keyPresets))
+	for name := range keyPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewKeyMapOverrides(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErr   string // Empty when the overrides are allowed
+	}{
+		{name: "letter for a turn action", overrides: map[string][]string{"roll": {"d"}}},
+		{name: "ctrl key while typing", overrides: map[string][]string{"debug": {"ctrl+d"}}},
+		{name: "letter while typing", overrides: map[string][]string{"challenge": {"c"}}, wantErr: `key "c" can't be bound to "challenge"`},
+		{name: "letter for debug", overrides: map[string][]string{"debug": {"d"}}, wantErr: `key "d" can't be bound to "debug"`},
+		{name: "alt letter for debug", overrides: map[string][]string{"debug": {"alt+d"}}, wantErr: `key "alt+d" can't be bound to "debug"`},
+		{name: "unknown action", overrides: map[string][]string{"jump": {"j"}}, wantErr: `unknown key action "jump"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyMap("", tt.overrides)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("newKeyMap(%v) returned %v, want no error", tt.overrides, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("newKeyMap(%v) returned %v, want %s", tt.overrides, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -11,36 +11,59 @@ import (
 * UI Configuration
 *************************************/
 const SIDEBAR_WIDTH = 15
-const DICE_WIDTH = 8
-
-// Colors
-const COLOR_BORDER = lipgloss.Color("#555555")
-const COLOR_HIGHLIGHT = lipgloss.Color("#FFFF00")
-const COLOR_LOGO_GREEN = lipgloss.Color("#9FE2BF")
-const COLOR_LOGO_BLUE = lipgloss.Color("#87CEEB")
-const COLOR_TEXT = lipgloss.Color("#EAEAEA")
-const COLOR_RED = lipgloss.Color("#963c31")
-const COLOR_BRIGHT_RED = lipgloss.Color("#C24D3F")
-const COLOR_BLUE = lipgloss.Color("#45657A")
-const COLOR_YELLOW = lipgloss.Color("#D9C380")
-const COLOR_AILMENT_ACTIVE = lipgloss.Color("#56787a")
-const COLOR_AILMENT_INACTIVE = lipgloss.Color("#333333")
+const DICE_WIDTH = 9
+const DICE_WIDTH_COMPACT = 5
+
+// Breakpoints
+const COMPACT_WIDTH = 60
+const COMPACT_HEIGHT = 34
+const MIN_WIDTH = 36
+const MIN_HEIGHT = 26
+
+// Small terminals get a single column layout with less padding
+func (m model) isCompact() bool {
+	return m.width < COMPACT_WIDTH || m.height < COMPACT_HEIGHT
+}
+
+func (m model) isTooSmall() bool {
+	return m.width < MIN_WIDTH || m.height < MIN_HEIGHT
+}
+
+// diceBoxStyle is shared by the dice and the re-roll choices beneath them
+func (m model) diceBoxStyle() lipgloss.Style {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		Padding(1, 2).
+		Align(lipgloss.Center).
+		Width(DICE_WIDTH)
+
+	if m.isCompact() {
+		style = style.Padding(0, 1).Width(DICE_WIDTH_COMPACT)
+	}
+
+	return style
+}
 
 func (m model) getHeader(width int) string {
 	prefixStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(COLOR_LOGO_GREEN)).
+		Foreground(m.theme.LogoPrefix).
 		Bold(true)
 
 	postfixStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(COLOR_LOGO_BLUE)).
+		Foreground(m.theme.LogoSuffix).
 		Bold(true)
 
 	logoText := prefixStyle.Render("Dice") + postfixStyle.Render("r")
 
+	verticalPadding := 1
+	if m.isCompact() {
+		verticalPadding = 0
+	}
+
 	headerStyle := lipgloss.NewStyle().
 		Width(width).
 		Align(lipgloss.Center).
-		Padding(1, 0).
+		Padding(verticalPadding, 0).
 		Border(lipgloss.NormalBorder()).
 		BorderBottom(true).
 		BorderTop(false).
@@ -51,10 +74,11 @@ func (m model) getHeader(width int) string {
 }
 
 func (m model) getStatusSidebar() string {
-	createStyle := func(background lipgloss.Color) lipgloss.Style {
+	createStyle := func(background lipgloss.TerminalColor) lipgloss.Style {
 		return lipgloss.NewStyle().
 			Background(background).
-			Foreground(COLOR_TEXT).
+			Foreground(m.theme.Text).
+			Reverse(m.theme.Monochrome).
 			Padding(1, 2).
 			Align(lipgloss.Center).
 			Width(SIDEBAR_WIDTH).
@@ -63,57 +87,130 @@ func (m model) getStatusSidebar() string {
 			Bold(true)
 	}
 
-	livesStyle := createStyle(COLOR_RED)
-	turnStyle := createStyle(COLOR_BLUE)
+	livesStyle := createStyle(m.theme.Lives)
+	turnStyle := createStyle(m.theme.Turn)
 
 	// Build content
-	livesText := fmt.Sprintf("Lives: %d", m.player.Lives)
-	turnText := fmt.Sprintf("Turn: %d", m.roundNumber)
+	livesText := fmt.Sprintf("Lives: %d", m.game.Player.Lives)
+	turnText := fmt.Sprintf("Turn: %d\nScore: %d", m.game.Round, m.game.Player.Score)
+
+	boxes := []string{livesStyle.Render(livesText), turnStyle.Render(turnText)}
+	if m.campaign != nil {
+		boxes = append(boxes, turnStyle.Render(m.levelText()))
+	}
+	if m.game.Rules.Items {
+		boxes = append(boxes, createStyle(m.theme.Items).Align(lipgloss.Left).Render(m.getItemsList()))
+	}
 
 	// Stack vertically
-	sidebar := lipgloss.JoinVertical(
-		lipgloss.Left,
-		livesStyle.Render(livesText),
-		turnStyle.Render(turnText),
-	)
+	return lipgloss.JoinVertical(lipgloss.Left, boxes...)
+}
+
+// getItemsList lists the inventory with the item cursor marked
+func (m model) getItemsList() string {
+	list := "Items"
+	if len(m.game.Player.Items) == 0 {
+		return list + "\n  none"
+	}
 
-	return sidebar
+	for i, kind := range m.game.Player.Items {
+		name := string(kind)
+		if item, ok := models.LookupItem(kind); ok {
+			name = item.Name()
+		}
+
+		marker := "  "
+		if i == m.itemCursor {
+			marker = "> "
+		}
+		list += "\n" + marker + name
+	}
+	return list
 }
 
-func (m model) getAilmentsBar(width int) string {
-	ailments := m.player.Ailments
-	availableWidth := width - len(ailments.Remaining) - 1
-	boxWidth := availableWidth / len(ailments.Remaining)
+// getStatusBar lays the sidebar out as a single centered line
+func (m model) getStatusBar(width int) string {
+	createStyle := func(background lipgloss.TerminalColor) lipgloss.Style {
+		return lipgloss.NewStyle().
+			Background(background).
+			Foreground(m.theme.Text).
+			Reverse(m.theme.Monochrome).
+			Padding(0, 1).
+			MarginRight(1).
+			Bold(true)
+	}
 
-	createBoxStyle := func(background lipgloss.Color) lipgloss.Style {
+	boxes := []string{
+		createStyle(m.theme.Lives).Render(fmt.Sprintf("Lives: %d", m.game.Player.Lives)),
+		createStyle(m.theme.Turn).Render(fmt.Sprintf("Turn: %d", m.game.Round)),
+		createStyle(m.theme.Turn).Render(fmt.Sprintf("Score: %d", m.game.Player.Score)),
+	}
+	if m.campaign != nil {
+		boxes = append(boxes, createStyle(m.theme.Turn).Render(m.levelText()))
+	}
+	if m.game.Rules.Items {
+		items := fmt.Sprintf("Items: %d", len(m.game.Player.Items))
+		if item, ok := m.selectedItem(); ok {
+			items += " " + item.Name()
+		}
+		boxes = append(boxes, createStyle(m.theme.Items).Render(items))
+	}
+
+	status := lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Align(lipgloss.Center).
+		Render(status)
+}
+
+func (m model) getAilmentsBar(width int) (string, []zone) {
+	ailments := m.game.Player.Ailments.List
+	availableWidth := width - len(ailments) - 1
+	boxWidth := 1
+	if len(ailments) > 0 {
+		boxWidth = max(availableWidth/len(ailments), 1)
+	}
+
+	verticalPadding := 1
+	if m.isCompact() {
+		verticalPadding = 0
+	}
+
+	createBoxStyle := func(background lipgloss.TerminalColor) lipgloss.Style {
 		return lipgloss.NewStyle().
 			Background(background).
-			Foreground(COLOR_TEXT).
-			Padding(1, 0).
+			Foreground(m.theme.Text).
+			Padding(verticalPadding, 0).
 			Align(lipgloss.Center).
 			Width(boxWidth).
 			MarginRight(1)
 	}
 
-	// Create 9 boxes, one for each ailment (1-9)
+	// Create a box for each ailment, colored by its kind
 	var boxes []string
-	for i := 1; i <= len(ailments.Remaining); i++ {
+	for _, ailment := range ailments {
 		var boxStyle lipgloss.Style
-		if ailments.HasAilment(i) {
-			boxStyle = createBoxStyle(COLOR_AILMENT_ACTIVE)
-		} else {
-			boxStyle = createBoxStyle(COLOR_AILMENT_INACTIVE)
+		switch {
+		case !ailment.IsActive():
+			boxStyle = createBoxStyle(m.theme.AilmentInactive).Faint(m.theme.Monochrome)
+		case ailment.Kind == models.AILMENT_CURSED:
+			boxStyle = createBoxStyle(m.theme.AilmentCursed).Reverse(m.theme.Monochrome)
+		case ailment.Kind == models.AILMENT_SPREADING:
+			boxStyle = createBoxStyle(m.theme.AilmentSpreading).Reverse(m.theme.Monochrome)
+		default:
+			boxStyle = createBoxStyle(m.theme.AilmentActive).Reverse(m.theme.Monochrome)
 		}
-		boxes = append(boxes, boxStyle.Render(fmt.Sprintf("%d", i)))
+		boxes = append(boxes, boxStyle.Bold(ailment.Hits > 1).Render(ailmentLabel(ailment, boxWidth)))
 	}
 
 	// Join boxes horizontally (margins will create the black line effect)
-	bar := lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+	bar, zones := joinRow(boxes)
 
 	// Style the bar container with full width and top border
 	barStyle := lipgloss.NewStyle().
 		Width(width).
-		Padding(1, 0).
+		Padding(verticalPadding, 0).
 		Border(lipgloss.NormalBorder()).
 		BorderTop(true).
 		BorderBottom(false).
@@ -121,42 +218,81 @@ func (m model) getAilmentsBar(width int) string {
 		BorderRight(false).
 		Align(lipgloss.Center)
 
-	return barStyle.Render(bar)
+	// Boxes sit below the border and padding, centered in the bar
+	left := (width - lipgloss.Width(bar)) / 2
+	for i := range zones {
+		zones[i] = zones[i].offset(left, barStyle.GetBorderTopSize()+barStyle.GetPaddingTop())
+	}
+
+	return barStyle.Render(bar), zones
 }
 
-func (m model) getDice(dice []models.Dice) string {
+// ailmentLabel marks ailments that need more hits, are cursed or are about to
+// spread, dropping the mark when the box is too narrow for it
+func ailmentLabel(ailment models.Ailment, width int) string {
+	label := fmt.Sprintf("%d", ailment.Value)
+
+	var mark string
+	switch {
+	case !ailment.IsActive():
+		return label
+	case ailment.Hits > 1:
+		mark = fmt.Sprintf("x%d", ailment.Hits)
+	case ailment.Kind == models.AILMENT_CURSED:
+		mark = "!"
+	case ailment.Kind == models.AILMENT_SPREADING:
+		mark = fmt.Sprintf("~%d", ailment.Spread)
+	}
+
+	if mark == "" || len(label)+len(mark)+1 > width {
+		return label
+	}
+	return label + " " + mark
+}
+
+func (m model) getDice(dice []models.Dice, used map[int]struct{}) (string, []zone) {
 	// Create a box style with border, no background, bold centered text
-	boxStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(COLOR_BORDER).
-		Padding(1, 2).
-		Align(lipgloss.Center).
-		Width(DICE_WIDTH).
+	// Faces are three rows tall, so they take the place of the vertical padding
+	boxStyle := m.diceBoxStyle().
+		BorderForeground(m.theme.Border).
+		PaddingTop(0).
+		PaddingBottom(0).
 		Bold(true)
 
+	// Dice already used in the expression are greyed out
+	usedStyle := boxStyle.
+		BorderForeground(m.theme.AilmentInactive).
+		Foreground(m.theme.AilmentInactive).
+		Faint(m.theme.Monochrome)
+
 	// Create boxes for each die
 	var boxes []string
-	for _, die := range dice {
-		boxes = append(boxes, boxStyle.Render(fmt.Sprintf("%d", die.Value)))
+	for i, die := range dice {
+		style := boxStyle
+		if _, isUsed := used[i]; isUsed {
+			style = usedStyle
+		}
+		boxes = append(boxes, style.Render(m.dieFace(m.displayValue(i, die), die.Sides)))
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+	return joinRow(boxes)
 }
 
-func (m model) getChoices() string {
+func (m model) getChoices() (string, []zone) {
 	// Create a box style matching dice width
 	createBoxStyle := func(isCursor bool, isSelected bool) lipgloss.Style {
-		borderColor := COLOR_BORDER
+		borderColor := m.theme.Border
+		border := lipgloss.RoundedBorder()
 		if isCursor {
-			borderColor = COLOR_HIGHLIGHT
+			borderColor = m.theme.Highlight
+			if m.theme.Monochrome {
+				border = lipgloss.ThickBorder()
+			}
 		}
 
-		style := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(borderColor).
-			Padding(1, 2).
-			Align(lipgloss.Center).
-			Width(DICE_WIDTH)
+		style := m.diceBoxStyle().
+			Border(border).
+			BorderForeground(borderColor)
 
 		if isSelected {
 			style = style.Bold(true)
@@ -182,58 +318,148 @@ func (m model) getChoices() string {
 		boxes = append(boxes, boxStyle.Render(content))
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, boxes...)
+	return joinRow(boxes)
+}
+
+func (m model) getButtons(state models.TurnPhase) (string, map[string]zone) {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Highlight).
+		Padding(0, 1).
+		MarginTop(1).
+		MarginRight(1)
+
+	labels := phaseButtons[state]
+
+	var boxes []string
+	for _, label := range labels {
+		boxes = append(boxes, style.Render(label))
+	}
+
+	row, zones := joinRow(boxes)
+
+	buttons := make(map[string]zone)
+	for i, label := range labels {
+		buttons[label] = zones[i]
+	}
+
+	return row, buttons
 }
 
-func (m model) getBoard(message string) string {
+func (m model) getBuiltExpression() string {
+	style := lipgloss.NewStyle().
+		PaddingTop(1).
+		Bold(true)
+
+	if len(m.builder.tokens) == 0 {
+		return style.Foreground(m.theme.Border).Render("> pick a die or (")
+	}
+	return style.Render("> " + m.builder.String())
+}
+
+func (m model) getBoard(message string, width int) (string, layoutZones) {
+	// Wrap the message here so row positions are known before the board is placed
 	contentStyle := lipgloss.NewStyle().
+		Width(width).
 		Padding(1, 2)
 
-	dice := m.getDice(m.turn.Dice)
+	if m.isCompact() {
+		contentStyle = contentStyle.Padding(1, 0)
+	}
+
+	turnState, _ := m.game.Turn.Stack.Top()
 
-	turnState, _ := m.turn.Stack.Top()
+	if m.inspect != "" {
+		message += "\n\n" + lipgloss.NewStyle().Foreground(m.theme.Instructions).Render(m.inspect)
+	}
+
+	var used map[int]struct{}
+	if turnState == models.GS_ExpressionPhase && m.building {
+		used = m.builder.used
+	}
+	dice, diceZones := m.getDice(m.game.Turn.Dice, used)
 
 	choices := ""
-	if turnState == models.GS_RollPhase {
-		choices = m.getChoices()
+	var choiceZones []zone
+	if m.isChoosingDice(turnState) {
+		choices, choiceZones = m.getChoices()
 	}
 
 	expression := ""
 	if turnState == models.GS_ExpressionPhase {
 		expression = m.textInput.View()
+		if m.building {
+			expression = m.getBuiltExpression()
+		}
+	}
+
+	buttons, buttonZones := m.getButtons(turnState)
+
+	content := contentStyle.Render(message)
+	if m.tutorial {
+		content = lipgloss.JoinVertical(lipgloss.Left, m.getTutorialHint(width), content)
+	}
+
+	// Track where each row starts so clicks can be mapped back to it
+	zones := layoutZones{buttons: make(map[string]zone)}
+	y := lipgloss.Height(content)
+	for _, z := range diceZones {
+		zones.dice = append(zones.dice, z.offset(0, y))
+	}
+	y += lipgloss.Height(dice)
+	for _, z := range choiceZones {
+		zones.choices = append(zones.choices, z.offset(0, y))
+	}
+	y += lipgloss.Height(choices) + lipgloss.Height(expression)
+	for label, z := range buttonZones {
+		zones.buttons[label] = z.offset(0, y)
 	}
 
 	mainContent := lipgloss.JoinVertical(
 		lipgloss.Top,
-		contentStyle.Render(message),
+		content,
 		dice,
 		choices,
 		expression,
+		buttons,
 	)
 
-	return mainContent
+	return mainContent, zones
 }
 
 func (m model) getInstructions(width int) string {
 	style := lipgloss.NewStyle().
-		Width(width/2 - 2).
+		Width(m.footerColumnWidth(width)).
 		Align(lipgloss.Left).
 		PaddingLeft(1).
-		Foreground(lipgloss.Color(COLOR_YELLOW))
+		Foreground(m.theme.Instructions)
 
-	return style.Render(m.instructions)
+	state, _ := m.game.Turn.Stack.Top()
+	bindings := m.keys.phaseBindings(state, m.building)
+	if state == models.GS_RollPhase && len(m.game.Player.Items) > 0 {
+		bindings = append(bindings, describe(m.keys.Item, "next item"), describe(m.keys.UseItem, "use item"))
+	}
+	return style.Render(m.help.ShortHelpView(bindings))
 }
 
 func (m model) getDebug(width int) string {
 	style := lipgloss.NewStyle().
-		Width(width/2 - 2).
+		Width(m.footerColumnWidth(width)).
 		Align(lipgloss.Right).
 		PaddingRight(1).
-		Foreground(lipgloss.Color(COLOR_BRIGHT_RED))
+		Foreground(m.theme.Error)
 
 	return style.Render(m.debug)
 }
 
+// Instructions and debug share the footer, or stack when the layout is compact
+func (m model) footerColumnWidth(width int) int {
+	if m.isCompact() {
+		return width - 2
+	}
+	return width/2 - 2
+}
+
 func (m model) getFooter(width int, instructions, debug string) string {
 	footerContent := lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -241,6 +467,10 @@ func (m model) getFooter(width int, instructions, debug string) string {
 		debug,
 	)
 
+	if m.isCompact() {
+		footerContent = lipgloss.JoinVertical(lipgloss.Left, instructions, debug)
+	}
+
 	footerStyle := lipgloss.NewStyle().
 		Width(width).
 		Align(lipgloss.Center)
@@ -249,32 +479,85 @@ func (m model) getFooter(width int, instructions, debug string) string {
 }
 
 func (m model) renderGameLayout(width, height int) string {
-	header := m.getHeader(m.width)
-	board := m.getBoard(m.message)
-	sidebar := m.getStatusSidebar()
-	ailmentsBar := m.getAilmentsBar(m.width)
-	instructions := m.getInstructions(m.width)
-	debug := m.getDebug(m.width)
-	// Board Math
-	boardHeight := height - lipgloss.Height(header) - lipgloss.Height(ailmentsBar) - 2
-	boardWidth := width - SIDEBAR_WIDTH - 1
-
-	// Style the main content area
-	boardStyle := lipgloss.NewStyle().
-		Width(boardWidth).
-		Height(boardHeight).
-		Padding(1, 2)
+	switch {
+	case width == 0 || height == 0:
+		// Nothing to lay out until the first WindowSizeMsg arrives
+		return ""
+	case m.isTooSmall():
+		return m.renderTooSmall(width, height)
+	}
 
-	boardContent := boardStyle.Render(board)
+	ui, _ := m.layoutGame(width, height)
+	return ui
+}
 
-	// Create the body: main content + sidebar
-	body := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		boardContent,
-		sidebar,
-	)
+func (m model) renderTooSmall(width, height int) string {
+	style := lipgloss.NewStyle().
+		Foreground(m.theme.Error).
+		Align(lipgloss.Center)
 
+	text := fmt.Sprintf("Terminal too small\n%d x %d\n\nResize to at least %d x %d", width, height, MIN_WIDTH, MIN_HEIGHT)
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, style.Render(text))
+}
+
+// layoutGame renders the full window and records where the clickable parts
+// of it ended up.
+func (m model) layoutGame(width, height int) (string, layoutZones) {
+	header := m.getHeader(width)
+	ailmentsBar, ailmentZones := m.getAilmentsBar(width)
+	instructions := m.getInstructions(width)
+	debug := m.getDebug(width)
 	footer := m.getFooter(width, instructions, debug)
+
+	var body string
+	var boardStyle lipgloss.Style
+	var zones layoutZones
+	bodyY := lipgloss.Height(header)
+
+	if m.isCompact() {
+		// Status goes above a full width board
+		status := m.getStatusBar(width)
+		boardHeight := height - lipgloss.Height(header) - lipgloss.Height(status) - lipgloss.Height(ailmentsBar) - lipgloss.Height(footer)
+
+		boardStyle = lipgloss.NewStyle().
+			Width(width).
+			Height(max(boardHeight, 0)).
+			Padding(0, 1)
+
+		var board string
+		board, zones = m.getBoard(m.message, width-boardStyle.GetHorizontalPadding())
+
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			status,
+			boardStyle.Render(board),
+		)
+		bodyY += lipgloss.Height(status)
+	} else {
+		sidebar := m.getStatusSidebar()
+
+		// Board Math
+		boardHeight := height - lipgloss.Height(header) - lipgloss.Height(ailmentsBar) - 2
+		boardWidth := width - SIDEBAR_WIDTH - 1
+
+		// Style the main content area
+		boardStyle = lipgloss.NewStyle().
+			Width(boardWidth).
+			Height(max(boardHeight, 0)).
+			Padding(1, 2)
+
+		var board string
+		board, zones = m.getBoard(m.message, boardWidth-boardStyle.GetHorizontalPadding())
+
+		// Create the body: main content + sidebar
+		body = lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			boardStyle.Render(board),
+			sidebar,
+		)
+	}
+
 	// Combine header, body, and ailments bar
 	ui := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -286,7 +569,72 @@ func (m model) renderGameLayout(width, height int) string {
 
 	fullWindowStyle := lipgloss.NewStyle().
 		Width(width).
-		Height(height)
+		Height(height).
+		MaxWidth(width).
+		MaxHeight(height)
+
+	// Board content sits inside the board padding, just below the header
+	zones = zones.offset(boardStyle.GetPaddingLeft(), bodyY+boardStyle.GetPaddingTop())
+
+	barY := lipgloss.Height(header) + lipgloss.Height(body)
+	for _, z := range ailmentZones {
+		zones.ailments = append(zones.ailments, z.offset(0, barY))
+	}
+
+	return fullWindowStyle.Render(ui), zones
+}
+
+/*************************************
+* Hit Testing
+*************************************/
+// zone is a clickable rectangle measured in terminal cells
+type zone struct {
+	x, y, width, height int
+}
+
+func (z zone) contains(x, y int) bool {
+	return x >= z.x && x < z.x+z.width && y >= z.y && y < z.y+z.height
+}
+
+func (z zone) offset(x, y int) zone {
+	return zone{z.x + x, z.y + y, z.width, z.height}
+}
+
+// layoutZones holds the clickable areas of a rendered layout
+type layoutZones struct {
+	dice     []zone
+	choices  []zone
+	ailments []zone
+	buttons  map[string]zone
+}
+
+func (zones layoutZones) offset(x, y int) layoutZones {
+	moved := layoutZones{buttons: make(map[string]zone)}
+	for _, z := range zones.dice {
+		moved.dice = append(moved.dice, z.offset(x, y))
+	}
+	for _, z := range zones.choices {
+		moved.choices = append(moved.choices, z.offset(x, y))
+	}
+	for _, z := range zones.ailments {
+		moved.ailments = append(moved.ailments, z.offset(x, y))
+	}
+	for label, z := range zones.buttons {
+		moved.buttons[label] = z.offset(x, y)
+	}
+	return moved
+}
+
+// joinRow joins boxes horizontally and returns the area each one covers
+func joinRow(boxes []string) (string, []zone) {
+	var zones []zone
+
+	x := 0
+	for _, box := range boxes {
+		width, height := lipgloss.Size(box)
+		zones = append(zones, zone{x, 0, width, height})
+		x += width
+	}
 
-	return fullWindowStyle.Render(ui)
+	return lipgloss.JoinHorizontal(lipgloss.Top, boxes...), zones
 }
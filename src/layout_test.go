@@ -0,0 +1,78 @@
+package main
+
+import (
+	"dicer/pkg/game"
+	"dicer/pkg/models"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// styles matches the escape codes for bold, faint and reverse, which the
+// colorless theme still uses
+var styles = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// layoutModel is a game waiting for an expression, with scripted dice and a
+// theme that doesn't depend on the terminal
+func layoutModel(width, height int) model {
+	m := initialModel(options{theme: noColorTheme, keys: defaultKeyMap(), rules: game.DefaultRules()})
+	m.game = game.CreateGameWithRules(m.rules, models.CreateScriptedSource(3, 4, 6, 2))
+	m.syncChoices()
+	m.width, m.height = width, height
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("r")},
+		{Type: tea.KeyEnter},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	return m
+}
+
+func TestRenderGameLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		setup  func(m *model)
+	}{
+		{name: "too_small", width: 35, height: 20},
+		{name: "compact", width: 50, height: 30},
+		{name: "full", width: 120, height: 40},
+		{name: "no_ailments", width: 120, height: 40, setup: func(m *model) {
+			m.game.Player.Ailments.List = nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := layoutModel(tt.width, tt.height)
+			if tt.setup != nil {
+				tt.setup(&m)
+			}
+			got := styles.ReplaceAllString(m.renderGameLayout(tt.width, tt.height), "")
+
+			path := filepath.Join("testdata", "layout_"+tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v, run go test -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("layout at %dx%d doesn't match %s, run go test -update if the change is intended\ngot:\n%s\nwant:\n%s", tt.width, tt.height, path, got, want)
+			}
+		})
+	}
+}
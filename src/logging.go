@@ -0,0 +1,40 @@
+package main
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/logger"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*************************************
+* Logging
+*************************************/
+// addLogFlags adds the flags that choose where logs go. Once the flags are
+// parsed, the returned function builds the logger options, logging to w
+// when no file is given. The game passes nil so nothing draws over it.
+func addLogFlags(flags *flag.FlagSet) func(w io.Writer) (logger.Options, error) {
+	example := filepath.Join(config.StateDir(), "debug.log")
+	file := flags.String("log-file", "", "append logs to this file, or set "+logger.ENV_LOG+" instead, e.g. "+example)
+	format := flags.String("log-format", "", "log format: "+strings.Join(logger.Formats(), ", "))
+	level := flags.String("log-level", "info", "lowest level to log: debug, info, warn or error")
+
+	return func(w io.Writer) (logger.Options, error) {
+		opts := logger.Options{File: *file, Writer: w, Format: *format}
+		if opts.File == "" {
+			opts.File = os.Getenv(logger.ENV_LOG)
+		}
+
+		// People read a terminal, programs read a file
+		if opts.Format == "" && opts.File == "" {
+			opts.Format = logger.FORMAT_COLOR
+		}
+
+		var err error
+		opts.Level, err = logger.ParseLevel(*level)
+		return opts, err
+	}
+}
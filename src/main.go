@@ -1,14 +1,20 @@
 package main
 
 import (
-	"dicer/pkg/config"
-	"dicer/pkg/math"
+	"dicer/pkg/game"
+	"dicer/pkg/logger"
 	"dicer/pkg/models"
-	"dicer/pkg/single"
-	"dicer/pkg/stack"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -17,143 +23,180 @@ import (
 * Bubble Tea Model
 *************************************/
 type model struct {
-	roundNumber  int
-	choices      []string
-	selected     map[int]struct{}
-	cursor       int
-	textInput    textinput.Model
-	message      string
-	player       models.Player
-	turn         *models.Turn
-	width        int
-	height       int
-	instructions string
-	debug        string
-}
-
-func initialModel() model {
+	options
+	game          *game.Game
+	campaign      *game.Campaign // Set while playing a campaign, which owns game
+	choices       []string
+	selected      map[int]struct{}
+	cursor        int
+	textInput     textinput.Model
+	help          help.Model
+	message       string
+	width         int
+	height        int
+	debug         string
+	history       []string
+	historyIndex  int
+	historyDraft  string
+	building      bool
+	builder       *expressionBuilder
+	inspect       string
+	inspectID     int
+	phase         models.TurnPhase // The phase handled last
+	suggestion    string           // The tutorial's expression for these dice
+	itemCursor    int
+	choosingValue bool // Waiting for the value a Nudge or Wild needs
+	itemNotice    string
+	rolling       rollAnimation
+	challenging   bool // Waiting for the solver to check a challenge
+	rejected      bool // The last expression submitted was invalid
+	menu          pauseMenu
+	debugPanel    bool
+	recentMsgs    []string // The latest messages, for the debug panel
+	renders       *renderTiming
+}
+
+func initialModel(opts options) model {
 	ti := textinput.New()
 	ti.Placeholder = "( x + y ) / z"
 	ti.Focus()
-	ti.CharLimit = 24
+	ti.CharLimit = 40
 	ti.Width = 24
 
-	var choices []string
-	for i := 0; i < config.NumDice; i++ {
-		choices = append(choices, "")
+	m := model{
+		options:   opts,
+		selected:  make(map[int]struct{}),
+		textInput: ti,
+		help:      newHelp(opts.theme),
+		builder:   newExpressionBuilder(),
+		message:   "Press any [ key ] to begin",
+		renders:   &renderTiming{},
 	}
 
-	return model{
-		roundNumber: 1,
-		selected:    make(map[int]struct{}),
-		choices:     choices,
-		textInput:   ti,
-		player:      models.CreatePlayer(config.MaxLives, config.NumAilments),
-		turn:        models.CreateTurn(1),
-		message:     "Press any [ key ] to begin",
+	if opts.campaign {
+		m.campaign = game.CreateCampaign(opts.seed)
+		m.game = m.campaign.Game
+	} else {
+		m.game = game.CreateGameWithRules(opts.rules, opts.diceSource())
 	}
+	m.syncChoices()
+
+	return m
 }
 
 func newModel(current *model) model {
-	model := initialModel()
+	model := initialModel(current.options)
 	model.height = current.height
 	model.width = current.width
+	model.building = current.building
+	model.debugPanel = current.debugPanel
 	return model
 }
 
 /*************************************
 * Model Utilities
 *************************************/
-func (m *model) checkGameOver() bool {
-	if !m.player.Ailments.HasAilments() {
-		return true
-	}
-	if !m.player.HasLives() {
-		return true
-	}
-	return false
-}
-
 func (m *model) endTurn() {
-	// Check game over condition first
-	if isGameOver := m.checkGameOver(); isGameOver != false {
-		m.turn.Stack.Push(models.GS_GameOver)
+	m.game.NextTurn()
+	if m.game.IsOver() {
 		return
 	}
 
 	// Set state for next turn
-	next := m.roundNumber + 1
-	m.roundNumber = next
-	m.turn = models.CreateTurn(next)
 	m.textInput.Reset()
+	m.builder = newExpressionBuilder()
 	m.selected = make(map[int]struct{})
 	m.cursor = 0
+	m.choosingValue = false
+	m.rejected = false
+	m.syncChoices()
 }
 
 func (m *model) getCurrentState() (models.TurnPhase, error) {
-	return m.turn.Stack.Top()
+	return m.game.Phase()
 }
 
 func (m *model) resetDice() {
-	m.turn.Dice = nil
+	m.game.Turn.Dice = nil
 }
 
-func (m *model) isValidExpression() bool {
-	exp := m.turn.Expression
-
-	isBalanced := math.IsBalancedParens(exp)
-	if !isBalanced {
-		m.debug = "Parenthesis not balanced"
-		return false
+func (m *model) submitExpression() {
+	if m.building {
+		m.textInput.SetValue(m.builder.String())
 	}
-
-	isSpaceDelimted := isSpaceDelimted(exp)
-	if !isSpaceDelimted {
-		m.debug = "Every character must be separated by a space"
-		return false
+	exp := m.textInput.Value()
+	m.addToHistory(exp)
+
+	// An invalid expression keeps the text so the player only has to fix the mistake
+	var expErr *game.ExpressionError
+	if err := m.game.Submit(exp); errors.As(err, &expErr) {
+		m.debug = expErr.Message
+		m.rejected = true
+		m.textInput.SetCursor(utf8.RuneCountInString(exp[:expErr.Pos]))
+		return
 	}
+	m.debug = ""
+	m.rejected = false
+}
 
-	numbers := &single.LinkedList{}
-	for num := range m.turn.Dice {
-		numbers.InsertAtHead(m.turn.Dice[num].Value)
+func (m *model) addToHistory(exp string) {
+	if strings.TrimSpace(exp) != "" && (len(m.history) == 0 || m.history[len(m.history)-1] != exp) {
+		m.history = append(m.history, exp)
 	}
+	m.historyIndex = len(m.history)
+	m.historyDraft = ""
+}
 
-	numOperators := 0
+// Step through earlier expressions, keeping whatever was being typed as a draft
+func (m *model) browseHistory(step int) {
+	next := m.historyIndex + step
+	if next < 0 || next > len(m.history) {
+		return
+	}
 
-	for _, char := range exp {
-		if math.IsOperand(string(char)) {
-			numbers.RemoveVal(int(char - '0'))
-		}
-		if math.IsOperator(string(char)) {
-			numOperators++
-		}
+	if m.historyIndex == len(m.history) {
+		m.historyDraft = m.textInput.Value()
 	}
+	m.historyIndex = next
 
-	if numbers.Head != nil {
-		m.debug = "Expression doesn't include all dice rolls"
-		return false
+	if next == len(m.history) {
+		m.textInput.SetValue(m.historyDraft)
+	} else {
+		m.textInput.SetValue(m.history[next])
 	}
+	m.textInput.CursorEnd()
+}
+
+// Switch between typing the expression and building it from the dice
+func (m *model) toggleBuilder() {
+	m.building = !m.building
+	m.cursor = 0
 
-	if numOperators != config.NumDice-1 {
-		m.debug = "Too many operators"
-		return false
+	if m.building {
+		m.builder = newExpressionBuilder()
+		m.textInput.Blur()
+		return
 	}
 
-	m.debug = ""
-	return true
+	m.textInput.SetValue(m.builder.String())
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
 }
 
-func (m *model) submitExpression() {
-	m.turn.Expression = m.textInput.Value()
-
-	if !m.isValidExpression() {
-		m.turn.Stack.Push(models.GS_ExpressionPhase)
-		m.textInput.Reset()
+func (m *model) addDieToExpression() {
+	if !m.builder.addDie(m.cursor, m.game.Turn.Dice[m.cursor].Value) {
+		m.debug = "That die can't go there"
 		return
 	}
+	m.debug = ""
+}
 
-	m.turn.Result = math.EvaluateExpression(m.turn.Expression)
-	m.turn.ApplyResult(&m.player)
+func (m *model) addSymbolToExpression(symbol string) {
+	if !m.builder.addSymbol(symbol, len(m.game.Turn.Dice)) {
+		m.debug = fmt.Sprintf("%s can't go there", symbol)
+		return
+	}
+	m.debug = ""
 }
 
 func (m *model) toggleDiceSelection() {
@@ -164,25 +207,6 @@ func (m *model) toggleDiceSelection() {
 	}
 }
 
-func isSpaceDelimted(exp string) bool {
-	stack := stack.StackList[rune]{}
-
-	for index, runeValue := range exp {
-		if index == 0 {
-			stack.Push(runeValue)
-			continue
-		}
-
-		lastRune, _ := stack.Top()
-		if runeValue != 32 && lastRune != 32 {
-			return false
-		}
-		stack.Push(runeValue)
-	}
-
-	return true
-}
-
 /*************************************
 * State Handlers
 *************************************/
@@ -199,50 +223,166 @@ var stateHandlers = map[models.TurnPhase]StateHandler{
 
 func handleTurnStart(m *model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.message = "Time to roll!"
-	m.instructions = "Press [ r ] to roll the dice"
+	if m.campaign != nil && m.game.Round == 1 {
+		m.message = m.levelIntro() + "\n" + m.message
+	}
+	for _, num := range m.game.Turn.Spread {
+		m.message += fmt.Sprintf("\nAn ailment spread to %d!", num)
+	}
 	return *m, nil
 }
 
 func handleRollPhase(m *model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.message = "Select which die to re-roll."
-	m.instructions = "[ left ] [ right ] to navigate [ space ] to toggle [ enter ] to submit"
+	switch {
+	case m.choosingValue:
+		m.message += "\n" + m.itemPrompt()
+	case m.itemNotice != "":
+		m.message += "\n" + m.itemNotice
+	}
 	return *m, nil
 }
 
 func handleExpressionPhase(m *model, msg tea.Msg) (tea.Model, tea.Cmd) {
-	m.message = "Type your expression! Ensure there is a space between each character. Valid operators include ( ) * / + -"
-	m.instructions = "[ enter ] to submit"
-	// if m.turn.Expression != "" {
-	// 	m.message = m.message + "\nInvalid expression. Try again."
-	// }
+	if m.challenging {
+		m.message = "Checking your challenge..."
+		return *m, nil
+	}
+
+	operators := strings.Split(m.game.Rules.AllowedOperators(), "")
+	m.message = "Type your expression! Ensure there is a space between each character. Valid operators include ( ) " + strings.Join(operators, " ")
+	m.message += fmt.Sprintf("\nIf nothing can be reached, press %s to challenge the roll.", m.keys.Challenge.Help().Key)
+	if m.rejected {
+		m.message = m.message + "\nInvalid expression. " + m.debug + ". Try again."
+	}
+	if m.building {
+		m.message = "Build your expression from the dice. Each die can be used once."
+		return *m, nil
+	}
+
 	m.textInput, _ = m.textInput.Update(msg)
 	return *m, nil
 }
 
 func handleResultsPhase(m *model, msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.resetDice()
-	enteredText := fmt.Sprintf("You entered %s which evaluates to %d.", m.turn.Expression, m.turn.Result)
+	if m.game.Turn.Challenged {
+		m.message = m.challengeResultsMessage()
+		return *m, nil
+	}
+	if len(m.game.Turn.Parts) > 1 {
+		m.message = m.comboResultsMessage()
+		return *m, nil
+	}
+
+	enteredText := fmt.Sprintf("You entered %s which evaluates to %d.", m.game.Turn.Expression, m.game.Turn.Result)
+	if part := m.game.Turn.Parts[0]; part.Canonical != "" && part.Canonical != strings.Join(strings.Fields(part.Expression), " ") {
+		enteredText += fmt.Sprintf("\nTidied up, that's %s.", part.Canonical)
+	}
 	var resultText string
-	if m.turn.LostLife {
-		resultText = fmt.Sprintf("You lost a life! %d lives remaining.", m.player.Lives)
-	} else if m.turn.RemovedAilment {
-		resultText = fmt.Sprintf("Hit! You removed %d.", m.turn.Result)
+	switch {
+	case m.game.Turn.Shielded:
+		resultText = "That would have cost a life, but your shield held."
+	case m.game.Turn.Cursed:
+		resultText = fmt.Sprintf("Cursed! %d wasn't the last ailment, so you lost a life. %d lives remaining.", m.game.Turn.Result, m.game.Player.Lives)
+	case m.game.Turn.LostLife:
+		resultText = m.livesLostText()
+	case m.game.Turn.RemovedAilment:
+		resultText = fmt.Sprintf("Hit! You removed %d.", m.game.Turn.Result)
+	case m.game.Turn.HitAilment:
+		resultText = fmt.Sprintf("Hit! %d is weakened but still there.", m.game.Turn.Result)
 	}
-	m.message = enteredText + "\n" + resultText
-	m.instructions = "[ space ] to continue"
+	m.message = enteredText + "\n" + resultText + m.policyText() + m.rewardsText()
 	return *m, nil
 }
 
+// challengeResultsMessage says whether the dice really couldn't reach anything
+func (m *model) challengeResultsMessage() string {
+	switch {
+	case m.game.Turn.Reveal == "":
+		return "Challenge upheld! Nothing could be reached with those dice, so the turn is skipped."
+	case m.game.Turn.Shielded:
+		return fmt.Sprintf("Challenge failed: %s would have hit. Your shield held.", m.game.Turn.Reveal)
+	}
+	return fmt.Sprintf("Challenge failed: %s would have hit.\n%s", m.game.Turn.Reveal, m.livesLostText())
+}
+
+// livesLostText counts the lives this turn cost
+func (m *model) livesLostText() string {
+	if m.game.Turn.LivesLost > 1 {
+		return fmt.Sprintf("You lost %d lives! %d lives remaining.", m.game.Turn.LivesLost, m.game.Player.Lives)
+	}
+	return fmt.Sprintf("You lost a life! %d lives remaining.", m.game.Player.Lives)
+}
+
+// policyText explains when the result policy changed what a miss cost
+func (m *model) policyText() string {
+	if m.game.Turn.PolicyNote == "" {
+		return ""
+	}
+	return "\n" + m.game.Turn.PolicyNote
+}
+
+// comboResultsMessage goes through a combo part by part
+func (m *model) comboResultsMessage() string {
+	lines := []string{fmt.Sprintf("You entered a combo of %d parts.", len(m.game.Turn.Parts))}
+	for _, part := range m.game.Turn.Parts {
+		var outcome string
+		switch {
+		case part.Cursed:
+			outcome = "cursed!"
+		case part.Removed:
+			outcome = "removed!"
+		case part.Hit:
+			outcome = "hit, but it's still there"
+		default:
+			outcome = "missed"
+		}
+		exp := part.Canonical
+		if exp == "" {
+			exp = part.Expression
+		}
+		lines = append(lines, fmt.Sprintf("  %s = %d, %s", exp, part.Result, outcome))
+	}
+
+	switch {
+	case m.game.Turn.Shielded:
+		lines = append(lines, "That would have cost a life, but your shield held.")
+	case m.game.Turn.LostLife:
+		lines = append(lines, m.livesLostText())
+	}
+	return strings.Join(lines, "\n") + m.policyText() + m.rewardsText()
+}
+
+// rewardsText adds the points and any item earned this turn to the results
+func (m *model) rewardsText() string {
+	var text string
+	if slices.ContainsFunc(m.game.Turn.Parts, func(part models.PartResult) bool { return part.Repeat && part.Hit }) {
+		text += "\nSame solution as last time, so it scores no points."
+	}
+	if m.game.Turn.Score > 0 {
+		text += fmt.Sprintf("\n+%d points.", m.game.Turn.Score)
+	}
+	if item, ok := models.LookupItem(m.game.Turn.EarnedItem); ok {
+		text += fmt.Sprintf("\nYou earned a %s: %s.", item.Name(), item.Description())
+	}
+	return text
+}
+
 func handleGameOver(m *model, msg tea.Msg) (tea.Model, tea.Cmd) {
-	if !m.player.Ailments.HasAilments() {
+	if m.campaign != nil {
+		m.message = m.campaignOverMessage()
+		return *m, nil
+	}
+
+	if !m.game.Player.Ailments.HasAilments() {
 		m.message = "You win! How good."
 	}
 
-	if !m.player.HasLives() {
+	if !m.game.Player.HasLives() {
 		m.message = "You lose! Bummer."
 	}
 
-	m.instructions = "[ enter ] to restart the game"
 	return *m, nil
 }
 
@@ -253,76 +393,226 @@ func (m model) processGameState(state models.TurnPhase, msg tea.Msg) (tea.Model,
 		return m, nil
 	}
 
+	if state != m.phase {
+		m.phase = state
+		m.updateSuggestion(state)
+	}
+
 	return handler(&m, msg)
 }
 
 /*************************************
 * Key handlers
 *************************************/
-// On [ enter ] press
-func (m *model) handleEnterKey(state models.TurnPhase) {
+// On [ confirm ] press
+func (m *model) handleConfirmKey(state models.TurnPhase) tea.Cmd {
 	switch state {
 	case models.GS_RollPhase:
-		m.turn.RollSelectedDice(m.selected)
-		m.turn.Stack.Pop()
+		m.game.Reroll(m.selected)
+		cmd := m.startRollAnimation(m.selected)
+		m.selected = make(map[int]struct{})
+		return cmd
 	case models.GS_ExpressionPhase:
+		if m.building && !m.builder.isComplete(len(m.game.Turn.Dice)) {
+			m.debug = "Use every die and close every ( before submitting"
+			return nil
+		}
 		m.submitExpression()
-		m.turn.Stack.Pop()
+	}
+	return nil
+}
+
+// challengeMsg carries the expression the solver found for a challenge, if
+// there was one
+type challengeMsg struct {
+	reveal string
+}
+
+// On [ challenge ] press. The solver can take a while, so it runs as a
+// command and the challenge is settled when it answers.
+func (m *model) handleChallengeKey(state models.TurnPhase) tea.Cmd {
+	if state != models.GS_ExpressionPhase {
+		return nil
+	}
+	search, err := m.game.StartChallenge()
+	if err != nil {
+		m.debug = err.Error()
+		return nil
+	}
+
+	m.debug = ""
+	m.challenging = true
+	return func() tea.Msg {
+		return challengeMsg{search()}
+	}
+}
+
+func (m *model) handleChallenge(msg challengeMsg) {
+	m.challenging = false
+	if err := m.game.ResolveChallenge(msg.reveal); err != nil {
+		m.debug = err.Error()
 	}
 }
 
-// On [ space ] press
-func (m *model) handleSpaceKey(state models.TurnPhase) {
+// On [ select ] press
+func (m *model) handleSelectKey(state models.TurnPhase) {
 	switch state {
 	case models.GS_RollPhase:
 		m.toggleDiceSelection()
+	case models.GS_ExpressionPhase:
+		if m.building {
+			m.addDieToExpression()
+		}
 	case models.GS_ResultsPhase:
 		m.endTurn()
 	}
 }
 
-// On [ r ] press
-func (m *model) handleRollKey(state models.TurnPhase) {
-	if state == models.GS_TurnStart {
-		m.turn.Stack.Pop()
-		m.turn.RollDice()
+// On [ roll ] press
+func (m *model) handleRollKey(state models.TurnPhase) tea.Cmd {
+	if state == models.GS_TurnStart && m.game.Roll() == nil {
+		return m.startRollAnimation(allDice(m.game.Turn.Dice))
+	}
+	return nil
+}
+
+// On [ builder ] press
+func (m *model) handleBuilderKey(state models.TurnPhase) {
+	if state == models.GS_ExpressionPhase {
+		m.toggleBuilder()
 	}
 }
 
-// On [ left key ] press
+// On [ + - * / ( ) ] press while building an expression
+func (m *model) handleOperatorKey(symbol string, state models.TurnPhase) {
+	if state == models.GS_ExpressionPhase && m.building {
+		m.addSymbolToExpression(symbol)
+	}
+}
+
+// On [ undo ] press while building an expression
+func (m *model) handleUndoKey(state models.TurnPhase) {
+	if state == models.GS_ExpressionPhase && m.building {
+		m.builder.undo()
+	}
+}
+
+// On [ die ] press, picking a die by position without moving the cursor there
+func (m *model) handleDieKey(pressed string, state models.TurnPhase) {
+	index, ok := dieIndex(pressed)
+	if !ok || index >= len(m.game.Turn.Dice) || !m.isChoosingDice(state) {
+		return
+	}
+
+	m.cursor = index
+	m.handleSelectKey(state)
+}
+
+// The dice cursor is active while re-rolling and while building an expression
+func (m *model) isChoosingDice(state models.TurnPhase) bool {
+	return state == models.GS_RollPhase || (state == models.GS_ExpressionPhase && m.building)
+}
+
+// On [ history prev ] press
+func (m *model) handleHistoryPrevKey(state models.TurnPhase) {
+	if state == models.GS_ExpressionPhase {
+		m.browseHistory(-1)
+	}
+}
+
+// On [ history next ] press
+func (m *model) handleHistoryNextKey(state models.TurnPhase) {
+	if state == models.GS_ExpressionPhase {
+		m.browseHistory(1)
+	}
+}
+
+// On [ left ] press
 func (m *model) handleLeftKey(state models.TurnPhase) {
-	if state == models.GS_RollPhase && m.cursor > 0 {
+	if m.isChoosingDice(state) && m.cursor > 0 {
 		m.cursor--
 	}
 }
 
 // On [ right ] press
 func (m *model) handleRightKey(state models.TurnPhase) {
-	if state == models.GS_RollPhase && m.cursor < len(m.choices)-1 {
+	if m.isChoosingDice(state) && m.cursor < len(m.choices)-1 {
 		m.cursor++
 	}
 }
 
+// On [ quit ] press, asking first if a game is in progress
+func (m *model) handleQuitKey() tea.Cmd {
+	if m.isGameInProgress() {
+		m.menu = pauseMenu{screen: MENU_CONFIRM, pending: ACTION_QUIT}
+		return nil
+	}
+	return tea.Quit
+}
+
+// The text input has focus while typing an expression
+func (m *model) isTyping(state models.TurnPhase) bool {
+	return state == models.GS_ExpressionPhase && !m.building
+}
+
 // Forward [ ] key presses
-func (m *model) handleKeyPress(key tea.KeyMsg, state models.TurnPhase) tea.Cmd {
-	switch key.String() {
-	case "ctrl+c", "q":
-		return tea.Quit
+func (m *model) handleKeyPress(msg tea.KeyMsg, state models.TurnPhase) tea.Cmd {
+	// Typed characters belong to the expression while the text input has focus
+	if m.isTyping(state) && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) {
+		return nil
+	}
+
+	switch {
+	case m.choosingValue:
+		m.handleItemValueKey(msg)
 
-	case "r":
-		m.handleRollKey(state)
+	case key.Matches(msg, m.keys.Quit):
+		return m.handleQuitKey()
 
-	case "left", "h":
+	case key.Matches(msg, m.keys.Pause):
+		m.openMenu()
+
+	case key.Matches(msg, m.keys.Roll):
+		return m.handleRollKey(state)
+
+	case key.Matches(msg, m.keys.Left):
 		m.handleLeftKey(state)
 
-	case "right", "l":
+	case key.Matches(msg, m.keys.Right):
 		m.handleRightKey(state)
 
-	case "enter":
-		m.handleEnterKey(state)
+	case key.Matches(msg, m.keys.HistoryPrev):
+		m.handleHistoryPrevKey(state)
+
+	case key.Matches(msg, m.keys.HistoryNext):
+		m.handleHistoryNextKey(state)
+
+	case key.Matches(msg, m.keys.Confirm):
+		return m.handleConfirmKey(state)
+
+	case key.Matches(msg, m.keys.Select):
+		m.handleSelectKey(state)
+
+	case key.Matches(msg, m.keys.Builder):
+		m.handleBuilderKey(state)
 
-	case " ":
-		m.handleSpaceKey(state)
+	case key.Matches(msg, m.keys.Undo):
+		m.handleUndoKey(state)
+
+	case key.Matches(msg, m.keys.Operator):
+		m.handleOperatorKey(msg.String(), state)
+
+	case key.Matches(msg, m.keys.Die):
+		m.handleDieKey(msg.String(), state)
+
+	case key.Matches(msg, m.keys.Item):
+		m.handleItemKey(state)
+
+	case key.Matches(msg, m.keys.UseItem):
+		m.handleUseItemKey(state)
+
+	case key.Matches(msg, m.keys.Challenge):
+		return m.handleChallengeKey(state)
 	}
 
 	return nil
@@ -332,6 +622,8 @@ func (m *model) handleKeyPress(key tea.KeyMsg, state models.TurnPhase) tea.Cmd {
 * Bubble Tea Functions
 *************************************/
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m.recordMsg(msg)
+
 	// Handle window resize early
 	if msg, ok := msg.(tea.WindowSizeMsg); ok {
 		m.width = msg.Width
@@ -339,33 +631,113 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if msg, ok := msg.(rollTickMsg); ok {
+		return m, m.handleRollTick(msg)
+	}
+
+	if msg, ok := msg.(inspectMsg); ok {
+		m.handleInspect(msg)
+		return m, nil
+	}
+
+	if msg, ok := msg.(challengeMsg); ok {
+		m.handleChallenge(msg)
+	}
+
 	// Get current state
 	currentState, err := m.getCurrentState()
 	if err != nil {
 		return m, tea.Quit
 	}
 
+	var cmd tea.Cmd
+
 	// Handle key messages
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(keyMsg, m.keys.ForceQuit) {
+			return m, tea.Quit
+		}
+		if key.Matches(keyMsg, m.keys.Debug) {
+			m.debugPanel = !m.debugPanel
+			return m, nil
+		}
+
+		// The menu takes every key while it's open
+		if m.menu.isOpen() {
+			return m.handleMenuKey(keyMsg)
+		}
+
+		// A cleared campaign level moves on to the next one
+		if key.Matches(keyMsg, m.keys.Confirm) && currentState == models.GS_GameOver && m.campaign != nil && !m.campaign.IsOver() {
+			m.advanceLevel()
+			return m.processGameState(models.GS_TurnStart, msg)
+		}
+
 		// Reset Game
-		if keyMsg.String() == "enter" && currentState == models.GS_GameOver {
+		if key.Matches(keyMsg, m.keys.Confirm) && currentState == models.GS_GameOver {
+			// Finishing the tutorial moves on to a real game
+			m.tutorial = false
 			model := newModel(&m)
 			return model, nil
 		}
 
-		if cmd := m.handleKeyPress(keyMsg, currentState); cmd != nil {
-			return m, cmd
+		// Any key settles dice that are still tumbling
+		if m.rolling.frames > 0 {
+			m.skipRollAnimation()
+			return m, nil
 		}
+
+		// Nothing else happens until the challenge has been checked
+		if m.challenging {
+			return m, nil
+		}
+
+		m.clearInspect()
+		m.itemNotice = ""
+		cmd = m.handleKeyPress(keyMsg, currentState)
 		// Update state after key handling
 		currentState, _ = m.getCurrentState()
 	}
 
+	// Handle left clicks on dice, ailments and buttons
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		if mouseMsg.Action != tea.MouseActionPress || mouseMsg.Button != tea.MouseButtonLeft || m.menu.isOpen() {
+			return m, nil
+		}
+
+		if m.rolling.frames > 0 {
+			m.skipRollAnimation()
+			return m, nil
+		}
+		if m.challenging {
+			return m, nil
+		}
+
+		var restart bool
+		if cmd, restart = m.handleClick(mouseMsg, currentState); restart {
+			return newModel(&m), nil
+		}
+		currentState, _ = m.getCurrentState()
+	}
+
 	// Process current game state
-	return m.processGameState(currentState, msg)
+	model, stateCmd := m.processGameState(currentState, msg)
+	return model, tea.Batch(cmd, stateCmd)
 }
 
 func (m model) View() string {
-	return m.renderGameLayout(m.width, m.height)
+	if m.menu.isOpen() && m.width > 0 {
+		return m.renderMenu(m.width, m.height)
+	}
+
+	start := time.Now()
+	ui := m.renderGameLayout(m.width, m.height)
+	m.renders.record(time.Since(start))
+
+	if m.debugPanel && m.width > 0 {
+		ui = m.overlayDebugPanel(ui)
+	}
+	return ui
 }
 
 /*************************************
@@ -376,9 +748,95 @@ func (m model) Init() tea.Cmd {
 }
 
 func main() {
-	p := tea.NewProgram(initialModel())
+	os.Exit(run(os.Args[1:]))
+}
+
+// run starts the game or a tool and returns the exit code, so deferred
+// cleanup such as closing the log happens before exiting
+func run(args []string) int {
+	// Tools that don't open the game
+	tools := map[string]func([]string) error{
+		"serve":    runServe,
+		"generate": runGenerate,
+	}
+	if len(args) > 0 {
+		if tool, ok := tools[args[0]]; ok {
+			if err := tool(args[1:]); err != nil && err != flag.ErrHelp {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			return 0
+		}
+	}
+
+	// "dicer play" is the same as "dicer", but reads better next to --plain
+	mode := "play"
+	if len(args) > 0 && (args[0] == "play" || args[0] == "puzzles" || args[0] == "campaign") {
+		mode = args[0]
+		args = args[1:]
+	}
+
+	opts, err := loadOptions(args)
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Only the classic game can be played in plain mode
+	if opts.plain && mode != "play" {
+		fmt.Fprintf(os.Stderr, "--plain can't be used with dicer %s\n", mode)
+		return 2
+	}
+
+	closeLog, err := logger.Setup(opts.log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer closeLog()
+
+	if opts.plain {
+		if err := runPlain(os.Stdin, os.Stdout, opts.rules, opts.diceSource()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	// A campaign brings its own rules, and the tutorial only knows the classic game
+	if mode == "campaign" {
+		opts.campaign = true
+		opts.tutorial = false
+	}
+
+	if mode == "puzzles" {
+		browser, err := newPuzzleBrowser(opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return runProgram(browser)
+	}
+
+	model := initialModel(opts)
+	if opts.resume {
+		if err := model.loadGame(savePath()); err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't resume the saved game: %v\n", err)
+			return 1
+		}
+	}
+
+	return runProgram(model)
+}
+
+func runProgram(model tea.Model) int {
+	p := tea.NewProgram(model, tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v\n", err)
+		return 1
 	}
+	return 0
 }
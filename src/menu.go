@@ -0,0 +1,295 @@
+package main
+
+import (
+	"dicer/pkg/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*************************************
+* Pause Menu
+*************************************/
+type menuScreen int
+
+const (
+	MENU_CLOSED menuScreen = iota
+	MENU_MAIN
+	MENU_RULES
+	MENU_SETTINGS
+	MENU_CONFIRM
+)
+
+type menuAction int
+
+const (
+	ACTION_RESUME menuAction = iota
+	ACTION_RESTART
+	ACTION_RULES
+	ACTION_TUTORIAL
+	ACTION_SETTINGS
+	ACTION_SAVE
+	ACTION_QUIT
+)
+
+var menuEntries = []struct {
+	label  string
+	action menuAction
+}{
+	{"Resume", ACTION_RESUME},
+	{"Restart", ACTION_RESTART},
+	{"Rules", ACTION_RULES},
+	{"Tutorial", ACTION_TUTORIAL},
+	{"Settings", ACTION_SETTINGS},
+	{"Save", ACTION_SAVE},
+	{"Quit", ACTION_QUIT},
+}
+
+// menuIndex is the position of action in the main menu
+func menuIndex(action menuAction) int {
+	for i, entry := range menuEntries {
+		if entry.action == action {
+			return i
+		}
+	}
+	return 0
+}
+
+var settingsEntries = []string{"Theme", "Animations", "Dice faces"}
+
+type pauseMenu struct {
+	screen  menuScreen
+	cursor  int // Selected entry, or the page on the rules screen
+	pending menuAction
+	notice  string
+}
+
+// Actions that throw away the current game
+var abandoningActions = map[menuAction]string{
+	ACTION_RESTART:  "Restart",
+	ACTION_TUTORIAL: "Switch modes",
+	ACTION_QUIT:     "Quit",
+}
+
+func (p pauseMenu) isOpen() bool {
+	return p.screen != MENU_CLOSED
+}
+
+func (m *model) openMenu() {
+	m.menu = pauseMenu{screen: MENU_MAIN}
+}
+
+// A game is in progress once the first roll has happened and until it's over
+func (m *model) isGameInProgress() bool {
+	state, _ := m.getCurrentState()
+	if state == models.GS_GameOver {
+		// A campaign is still going between levels
+		return m.campaign != nil && !m.campaign.IsOver()
+	}
+	return m.game.Round > 1 || state != models.GS_TurnStart
+}
+
+// requestAction runs action straight away, or asks first if it would throw
+// away a game in progress.
+func (m *model) requestAction(action menuAction) (tea.Model, tea.Cmd) {
+	if _, abandons := abandoningActions[action]; abandons && m.isGameInProgress() {
+		m.menu = pauseMenu{screen: MENU_CONFIRM, pending: action}
+		return *m, nil
+	}
+	return m.runAction(action)
+}
+
+func (m *model) runAction(action menuAction) (tea.Model, tea.Cmd) {
+	switch action {
+	case ACTION_RESUME:
+		m.menu = pauseMenu{}
+	case ACTION_RESTART:
+		return newModel(m), nil
+	case ACTION_RULES:
+		m.menu = pauseMenu{screen: MENU_RULES}
+	case ACTION_TUTORIAL:
+		m.tutorial = !m.tutorial
+		return newModel(m), nil
+	case ACTION_SETTINGS:
+		m.menu = pauseMenu{screen: MENU_SETTINGS}
+	case ACTION_SAVE:
+		path, err := m.saveGame()
+		if err != nil {
+			m.menu.notice = fmt.Sprintf("Couldn't save: %v", err)
+		} else {
+			m.menu.notice = fmt.Sprintf("Saved to %s", path)
+		}
+	case ACTION_QUIT:
+		return *m, tea.Quit
+	}
+	return *m, nil
+}
+
+func (m *model) handleMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.menu.screen {
+	case MENU_MAIN:
+		switch {
+		case key.Matches(msg, m.keys.Pause):
+			m.menu = pauseMenu{}
+		case key.Matches(msg, m.keys.Up):
+			m.menu.cursor = max(m.menu.cursor-1, 0)
+		case key.Matches(msg, m.keys.Down):
+			m.menu.cursor = min(m.menu.cursor+1, len(menuEntries)-1)
+		case key.Matches(msg, m.keys.Confirm, m.keys.Select):
+			return m.requestAction(menuEntries[m.menu.cursor].action)
+		}
+
+	case MENU_RULES:
+		switch {
+		case key.Matches(msg, m.keys.Pause):
+			m.menu = pauseMenu{screen: MENU_MAIN, cursor: menuIndex(ACTION_RULES)}
+		case key.Matches(msg, m.keys.Left, m.keys.Up):
+			m.menu.cursor = max(m.menu.cursor-1, 0)
+		case key.Matches(msg, m.keys.Right, m.keys.Down, m.keys.Confirm, m.keys.Select):
+			m.menu.cursor = min(m.menu.cursor+1, len(rulesPages)-1)
+		}
+
+	case MENU_SETTINGS:
+		switch {
+		case key.Matches(msg, m.keys.Pause):
+			m.menu = pauseMenu{screen: MENU_MAIN, cursor: menuIndex(ACTION_SETTINGS)}
+		case key.Matches(msg, m.keys.Up):
+			m.menu.cursor = max(m.menu.cursor-1, 0)
+		case key.Matches(msg, m.keys.Down):
+			m.menu.cursor = min(m.menu.cursor+1, len(settingsEntries)-1)
+		case key.Matches(msg, m.keys.Confirm, m.keys.Select, m.keys.Right):
+			m.changeSetting(m.menu.cursor, 1)
+		case key.Matches(msg, m.keys.Left):
+			m.changeSetting(m.menu.cursor, -1)
+		}
+
+	case MENU_CONFIRM:
+		switch {
+		case key.Matches(msg, m.keys.Yes, m.keys.Confirm):
+			return m.runAction(m.menu.pending)
+		case key.Matches(msg, m.keys.No, m.keys.Pause):
+			m.menu = pauseMenu{}
+		}
+	}
+
+	return *m, nil
+}
+
+// changeSetting steps the setting at index forwards or backwards
+func (m *model) changeSetting(index int, step int) {
+	switch settingsEntries[index] {
+	case "Theme":
+		names := make([]string, 0, len(builtinThemes))
+		for name := range builtinThemes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		current := sort.SearchStrings(names, m.theme.Name)
+		next := (current + step + len(names)) % len(names)
+		m.theme = builtinThemes[names[next]]
+		m.help = newHelp(m.theme)
+	case "Animations":
+		m.animate = !m.animate
+	case "Dice faces":
+		m.accessible = !m.accessible
+	}
+}
+
+func (m model) settingValue(name string) string {
+	switch name {
+	case "Theme":
+		return m.theme.Name
+	case "Animations":
+		if m.animate {
+			return "on"
+		}
+		return "off"
+	case "Dice faces":
+		if m.accessible {
+			return "digits"
+		}
+		return "pips"
+	}
+	return ""
+}
+
+/*************************************
+* Menu Layout
+*************************************/
+func (m model) renderMenu(width, height int) string {
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Highlight).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(m.theme.LogoPrefix).
+		Bold(true).
+		MarginBottom(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Instructions).
+		MarginTop(1)
+
+	var title, body, hint string
+
+	switch m.menu.screen {
+	case MENU_MAIN:
+		title = "Paused"
+		labels := make([]string, len(menuEntries))
+		for i, entry := range menuEntries {
+			labels[i] = entry.label
+			if entry.action == ACTION_TUTORIAL && m.tutorial {
+				labels[i] = "End tutorial"
+			}
+		}
+		body = m.renderMenuList(labels)
+		if m.menu.notice != "" {
+			body += "\n\n" + lipgloss.NewStyle().Foreground(m.theme.Instructions).Render(m.menu.notice)
+		}
+		hint = "enter select • esc resume"
+	case MENU_RULES:
+		page := rulesPages[m.menu.cursor]
+		title = fmt.Sprintf("Rules: %s (%d/%d)", page.title, m.menu.cursor+1, len(rulesPages))
+		body = page.body
+		hint = "left/right page • esc back"
+	case MENU_SETTINGS:
+		title = "Settings"
+		labels := make([]string, len(settingsEntries))
+		for i, name := range settingsEntries {
+			labels[i] = fmt.Sprintf("%-12s %s", name, m.settingValue(name))
+		}
+		body = m.renderMenuList(labels)
+		hint = "left/right change • esc back"
+	case MENU_CONFIRM:
+		title = "Abandon this game?"
+		body = fmt.Sprintf("%s and lose your progress?", abandoningActions[m.menu.pending])
+		hint = "y yes • n no"
+	}
+
+	box := boxStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(title),
+		body,
+		hintStyle.Render(hint),
+	))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+func (m model) renderMenuList(labels []string) string {
+	lines := make([]string, len(labels))
+	for i, label := range labels {
+		if i == m.menu.cursor {
+			lines[i] = lipgloss.NewStyle().Foreground(m.theme.Highlight).Bold(true).Render("> " + label)
+		} else {
+			lines[i] = "  " + label
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+/*************************************
+* Mouse Handling
+*************************************/
+const (
+	BUTTON_ROLL     = "Roll"
+	BUTTON_SUBMIT   = "Submit"
+	BUTTON_CONTINUE = "Continue"
+	BUTTON_RESTART  = "Restart"
+)
+
+// phaseButtons lists the clickable buttons shown under the board in each phase
+var phaseButtons = map[models.TurnPhase][]string{
+	models.GS_TurnStart:       {BUTTON_ROLL},
+	models.GS_RollPhase:       {BUTTON_ROLL},
+	models.GS_ExpressionPhase: {BUTTON_SUBMIT},
+	models.GS_ResultsPhase:    {BUTTON_CONTINUE},
+	models.GS_GameOver:        {BUTTON_RESTART},
+}
+
+// handleClick maps a left click back through the layout and performs the same
+// action as the matching key press. Restarting replaces the model entirely.
+func (m *model) handleClick(msg tea.MouseMsg, state models.TurnPhase) (cmd tea.Cmd, restart bool) {
+	if m.isTooSmall() {
+		return nil, false
+	}
+	m.clearInspect()
+
+	_, zones := m.layoutGame(m.width, m.height)
+
+	for label, z := range zones.buttons {
+		if z.contains(msg.X, msg.Y) {
+			return m.clickButton(label, state)
+		}
+	}
+
+	for i := range m.game.Turn.Dice {
+		if (i < len(zones.dice) && zones.dice[i].contains(msg.X, msg.Y)) ||
+			(i < len(zones.choices) && zones.choices[i].contains(msg.X, msg.Y)) {
+			m.clickDie(i, state)
+			return nil, false
+		}
+	}
+
+	for i, z := range zones.ailments {
+		if z.contains(msg.X, msg.Y) {
+			return m.inspectAilment(m.game.Player.Ailments.List[i]), false
+		}
+	}
+
+	return nil, false
+}
+
+func (m *model) clickButton(label string, state models.TurnPhase) (tea.Cmd, bool) {
+	switch label {
+	case BUTTON_ROLL:
+		if state == models.GS_TurnStart {
+			return m.handleRollKey(state), false
+		}
+		return m.handleConfirmKey(state), false
+	case BUTTON_SUBMIT:
+		return m.handleConfirmKey(state), false
+	case BUTTON_CONTINUE:
+		m.handleSelectKey(state)
+	case BUTTON_RESTART:
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m *model) clickDie(index int, state models.TurnPhase) {
+	if !m.isChoosingDice(state) {
+		return
+	}
+
+	m.cursor = index
+	m.handleSelectKey(state)
+}
+
+// inspectMsg carries what the solver found for a clicked ailment
+type inspectMsg struct {
+	id   int
+	text string
+}
+
+// inspectAilment explains an ailment and whether it can be hit with the
+// current dice. The solver can take a while, so it runs as a command.
+func (m *model) inspectAilment(ailment models.Ailment) tea.Cmd {
+	num := ailment.Value
+	switch {
+	case !ailment.IsActive():
+		m.inspect = fmt.Sprintf("Ailment %d has already been removed.", num)
+		return nil
+	case len(m.game.Turn.Dice) == 0:
+		m.inspect = fmt.Sprintf("Roll the dice to see if ailment %d can be reached.", num) + ailmentDetails(ailment)
+		return nil
+	}
+
+	m.inspect = fmt.Sprintf("Checking whether ailment %d can be reached...", num)
+	id, values, operators := m.inspectID, m.diceValues(), m.game.Rules.AllowedOperators()
+	return func() tea.Msg {
+		text := fmt.Sprintf("Ailment %d can't be reached with these dice.", num)
+		if math.IsReachableUsing(values, num, operators) {
+			text = fmt.Sprintf("Ailment %d can be reached with these dice.", num)
+		}
+		return inspectMsg{id, text + ailmentDetails(ailment)}
+	}
+}
+
+func (m *model) handleInspect(msg inspectMsg) {
+	// Answers for an inspection that was cleared since are dropped
+	if msg.id == m.inspectID {
+		m.inspect = msg.text
+	}
+}
+
+// clearInspect hides the inspected ailment, along with any answer still on
+// its way
+func (m *model) clearInspect() {
+	m.inspect = ""
+	m.inspectID++
+}
+
+func ailmentDetails(ailment models.Ailment) string {
+	switch {
+	case ailment.Hits > 1:
+		return fmt.Sprintf(" It needs %d more hits.", ailment.Hits)
+	case ailment.Kind == models.AILMENT_CURSED:
+		return " It's cursed, so hitting it costs a life unless it's the last one left."
+	case ailment.Kind == models.AILMENT_SPREADING:
+		return fmt.Sprintf(" It spreads in %d turns.", ailment.Spread)
+	}
+	return ""
+}
+
+func (m *model) diceValues() []int {
+	values := make([]int, len(m.game.Turn.Dice))
+	for i, die := range m.game.Turn.Dice {
+		values[i] = die.Value
+	}
+	return values
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/game"
+	"dicer/pkg/logger"
+	"dicer/pkg/models"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+/*************************************
+* Options
+*************************************/
+// options are read from the command line and config file at startup and
+// survive restarting the game
+type options struct {
+	theme      Theme
+	keys       keyMap
+	accessible bool
+	animate    bool
+	resume     bool
+	tutorial   bool
+	plain      bool
+	seed       uint64
+	pack       string
+	rules      game.Rules
+	campaign   bool
+	log        logger.Options
+}
+
+func loadOptions(args []string) (options, error) {
+	flags := flag.NewFlagSet("dicer", flag.ContinueOnError)
+	themeName := flags.String("theme", "", "color theme: auto, dark, light, high-contrast, colorblind, none, or one from the config file")
+	configPath := flags.String("config", config.DefaultPath(), "path to the config file")
+	keyPreset := flags.String("keys", "", "key preset: "+strings.Join(keyPresetNames(), ", "))
+	resume := flags.Bool("resume", false, "continue the game saved from the menu")
+	tutorial := flags.Bool("tutorial", false, "learn to play with a guided game")
+	accessible := flags.Bool("accessible", false, "plain digits for dice and no animations")
+	plain := flags.Bool("plain", false, "read commands from stdin and write the game state as JSON lines")
+	seed := flags.Uint64("seed", 0, "roll the same dice every time for a given seed")
+	pack := flags.String("pack", "", "puzzle pack to play with dicer puzzles, instead of the starter pack")
+	rulesName := flags.String("rules", "", "rule set: "+strings.Join(game.RuleSetNames(), ", "))
+	logOptions := addLogFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return options{}, err
+	}
+	if flags.NArg() > 0 {
+		return options{}, fmt.Errorf("unexpected argument %q, the mode goes before any flags", flags.Arg(0))
+	}
+
+	// Plain mode draws nothing, so themes and keys don't matter. Its JSON
+	// goes to stdout, so logs go to stderr.
+	if *plain {
+		logOpts, err := logOptions(os.Stderr)
+		if err != nil {
+			return options{}, err
+		}
+		rules, err := selectRules(*rulesName, *tutorial)
+		if err != nil {
+			return options{}, err
+		}
+		return options{plain: true, tutorial: *tutorial, seed: *seed, rules: rules, log: logOpts}, nil
+	}
+
+	// The game's screen is stdout, so logs only go to a file
+	logOpts, err := logOptions(nil)
+	if err != nil {
+		return options{}, err
+	}
+
+	settings, err := config.Load(*configPath)
+	if err != nil {
+		return options{}, fmt.Errorf("reading %s: %w", *configPath, err)
+	}
+
+	ruleSet := settings.Rules
+	if *rulesName != "" {
+		ruleSet = *rulesName
+	}
+
+	rules, err := selectRules(ruleSet, *tutorial)
+	if err != nil {
+		return options{}, err
+	}
+
+	name := settings.Theme
+	if *themeName != "" {
+		name = *themeName
+	}
+
+	theme, err := selectTheme(name, settings.Themes)
+	if err != nil {
+		return options{}, err
+	}
+
+	preset := settings.KeyPreset
+	if *keyPreset != "" {
+		preset = *keyPreset
+	}
+
+	keys, err := newKeyMap(preset, settings.Keys)
+	if err != nil {
+		return options{}, err
+	}
+
+	// Animations only make sense when someone is watching a real terminal
+	animate := !*accessible && term.IsTerminal(os.Stdout.Fd())
+
+	return options{theme: theme, keys: keys, accessible: *accessible, animate: animate, resume: *resume, tutorial: *tutorial, seed: *seed, pack: *pack, rules: rules, log: logOpts}, nil
+}
+
+// selectRules looks up a rule set by name. The tutorial's scripted dice only
+// make sense with the classic rules, so it always uses them.
+func selectRules(name string, tutorial bool) (game.Rules, error) {
+	if name == "" || tutorial {
+		return game.DefaultRules(), nil
+	}
+	return game.LookupRules(name)
+}
+
+// diceSource picks the dice for a new game. The tutorial always plays the same
+// game, and a seed repeats the same rolls.
+func (opts options) diceSource() models.DiceSource {
+	if opts.tutorial {
+		return models.CreateScriptedSource(tutorialDice...)
+	}
+
+	// The debug panel shows the seed so the game can be replayed
+	seed := opts.seed
+	if seed == 0 {
+		seed = models.NewSeed()
+	}
+	return models.CreateSeededSource(seed)
+}
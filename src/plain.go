@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"dicer/pkg/game"
+	"dicer/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*************************************
+* Plain Mode
+*************************************/
+// plainState is written as one JSON line after every command
+type plainState struct {
+	game.State
+	Error    string `json:"error,omitempty"`
+	ErrorPos *int   `json:"errorPos,omitempty"`
+}
+
+const PLAIN_COMMANDS = "roll, reroll [die ...], use <item> [die] [value], submit <expression>, challenge, continue, state, new, quit"
+
+// runPlain plays games by reading one command per line from in, with no
+// terminal UI at all. Dice and items are numbered from 1.
+func runPlain(in io.Reader, out io.Writer, rules game.Rules, source models.DiceSource) error {
+	g := game.CreateGameWithRules(rules, source)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(newPlainState(g, nil)); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		command, args, _ := strings.Cut(line, " ")
+		var err error
+		switch command {
+		case "roll":
+			err = g.Roll()
+		case "reroll":
+			err = plainReroll(g, args)
+		case "use":
+			err = plainUseItem(g, args)
+		case "submit":
+			err = g.Submit(strings.TrimSpace(args))
+		case "challenge":
+			err = g.Challenge()
+		case "continue", "next":
+			err = g.NextTurn()
+		case "state":
+		case "new":
+			g = game.CreateGameWithRules(rules, source)
+		case "quit", "exit":
+			return nil
+		default:
+			err = fmt.Errorf("unknown command %q, expected one of: %s", command, PLAIN_COMMANDS)
+		}
+
+		if err := encoder.Encode(newPlainState(g, err)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func plainReroll(g *game.Game, args string) error {
+	selected := make(map[int]struct{})
+	for _, field := range strings.Fields(args) {
+		num, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("%q isn't a die number", field)
+		}
+		selected[num-1] = struct{}{}
+	}
+	return g.Reroll(selected)
+}
+
+// plainUseItem reads "item [die] [value]", all numbers, for the item's effect
+func plainUseItem(g *game.Game, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 3 {
+		return fmt.Errorf("use takes an item number, then a die and a value if the item needs them")
+	}
+
+	nums := make([]int, 3)
+	for i, field := range fields {
+		num, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("%q isn't a number", field)
+		}
+		nums[i] = num
+	}
+
+	return g.UseItem(nums[0]-1, models.ItemUse{Die: nums[1] - 1, Value: nums[2]})
+}
+
+func newPlainState(g *game.Game, err error) plainState {
+	state := plainState{State: g.State()}
+
+	if err != nil {
+		state.Error = err.Error()
+		var expErr *game.ExpressionError
+		if errors.As(err, &expErr) {
+			state.ErrorPos = &expErr.Pos
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/game"
+	"dicer/pkg/puzzle"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*************************************
+* Puzzle Browser
+*************************************/
+// puzzleBrowser lists the puzzles in a pack and lets the player work through
+// the targets of one at a time. Solutions are saved as they're found.
+type puzzleBrowser struct {
+	options
+	pack         puzzle.Pack
+	progress     puzzle.Progress
+	progressPath string
+	cursor       int
+	open         bool
+	textInput    textinput.Model
+	help         help.Model
+	notice       string
+	debug        string
+	width        int
+	height       int
+}
+
+func progressPath() string {
+	return filepath.Join(config.StateDir(), "puzzles.json")
+}
+
+func newPuzzleBrowser(opts options) (puzzleBrowser, error) {
+	pack := puzzle.Starter()
+	if opts.pack != "" {
+		var err error
+		if pack, err = puzzle.Load(opts.pack); err != nil {
+			return puzzleBrowser{}, fmt.Errorf("loading %s: %w", opts.pack, err)
+		}
+	}
+
+	path := progressPath()
+	progress, err := puzzle.LoadProgress(path)
+	if err != nil {
+		return puzzleBrowser{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	ti := textinput.New()
+	ti.Placeholder = "( x + y ) / z"
+	ti.CharLimit = 24
+	ti.Width = 24
+
+	return puzzleBrowser{
+		options:      opts,
+		pack:         pack,
+		progress:     progress,
+		progressPath: path,
+		textInput:    ti,
+		help:         newHelp(opts.theme),
+	}, nil
+}
+
+func (b puzzleBrowser) current() puzzle.Puzzle {
+	return b.pack.Puzzles[b.cursor]
+}
+
+func (b *puzzleBrowser) openPuzzle() {
+	if len(b.pack.Puzzles) == 0 {
+		return
+	}
+	b.open = true
+	b.notice = ""
+	b.debug = ""
+	b.textInput.Reset()
+	b.textInput.Focus()
+}
+
+func (b *puzzleBrowser) submit() {
+	exp := b.textInput.Value()
+	p := b.current()
+
+	target, err := p.Check(exp)
+	var expErr *game.ExpressionError
+	if errors.As(err, &expErr) {
+		b.debug = expErr.Message
+		b.textInput.SetCursor(utf8.RuneCountInString(exp[:expErr.Pos]))
+		return
+	}
+
+	b.debug = ""
+	b.textInput.Reset()
+
+	_, solvedBefore := b.progress.Best(b.pack, p, target)
+	if !b.progress.Record(b.pack, p, target, exp) {
+		b.notice = fmt.Sprintf("%d again, but no shorter than your best.", target)
+		return
+	}
+
+	b.notice = fmt.Sprintf("Hit %d!", target)
+	if solvedBefore {
+		b.notice = fmt.Sprintf("Hit %d with a new best!", target)
+	}
+	if err := b.progress.Save(b.progressPath); err != nil {
+		b.debug = fmt.Sprintf("Couldn't save progress: %v", err)
+	}
+}
+
+func (b puzzleBrowser) Init() tea.Cmd {
+	return tea.EnterAltScreen
+}
+
+func (b puzzleBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		b.width = msg.Width
+		b.height = msg.Height
+		return b, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return b, nil
+	}
+	if key.Matches(keyMsg, b.keys.ForceQuit) {
+		return b, tea.Quit
+	}
+
+	if b.open {
+		switch {
+		case key.Matches(keyMsg, b.keys.Pause):
+			b.open = false
+		case key.Matches(keyMsg, b.keys.Confirm):
+			b.submit()
+		default:
+			b.textInput, _ = b.textInput.Update(msg)
+		}
+		return b, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, b.keys.Quit, b.keys.Pause):
+		return b, tea.Quit
+	case key.Matches(keyMsg, b.keys.Up, b.keys.Left):
+		b.cursor = max(b.cursor-1, 0)
+	case key.Matches(keyMsg, b.keys.Down, b.keys.Right):
+		b.cursor = min(b.cursor+1, len(b.pack.Puzzles)-1)
+	case key.Matches(keyMsg, b.keys.Confirm, b.keys.Select):
+		b.openPuzzle()
+	}
+	return b, nil
+}
+
+/*************************************
+* Puzzle Layout
+*************************************/
+func (b puzzleBrowser) View() string {
+	if b.width == 0 {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(b.theme.LogoPrefix).
+		Bold(true).
+		MarginBottom(1)
+
+	var title, body string
+	var bindings []key.Binding
+	if b.open {
+		title = fmt.Sprintf("Puzzle %s", b.current().ID)
+		body = b.renderPuzzle()
+		bindings = []key.Binding{describe(b.keys.Confirm, "submit"), describe(b.keys.Pause, "back")}
+	} else {
+		title = fmt.Sprintf("Puzzles: %s", b.pack.Name)
+		body = b.renderList()
+		bindings = []key.Binding{
+			describe(b.keys.Up, "up"),
+			describe(b.keys.Down, "down"),
+			describe(b.keys.Confirm, "play"),
+			describe(b.keys.Quit, "quit"),
+		}
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Render(title),
+		body,
+		lipgloss.NewStyle().MarginTop(1).Render(b.help.ShortHelpView(bindings)),
+	)
+
+	return lipgloss.NewStyle().
+		Padding(1, 2).
+		MaxWidth(b.width).
+		MaxHeight(b.height).
+		Render(content)
+}
+
+func (b puzzleBrowser) renderList() string {
+	if len(b.pack.Puzzles) == 0 {
+		return "This pack has no puzzles."
+	}
+
+	lines := make([]string, len(b.pack.Puzzles))
+	for i, p := range b.pack.Puzzles {
+		solved := b.progress.Solved(b.pack, p)
+
+		status := "[ ]"
+		if solved == len(p.Targets) {
+			status = "[x]"
+		} else if solved > 0 {
+			status = "[~]"
+		}
+
+		line := fmt.Sprintf("%s %-16s dice %-10s %d/%d", status, p.ID, joinInts(p.Dice), solved, len(p.Targets))
+		if i == b.cursor {
+			lines[i] = lipgloss.NewStyle().Foreground(b.theme.Highlight).Bold(true).Render("> " + line)
+		} else {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b puzzleBrowser) renderPuzzle() string {
+	p := b.current()
+
+	// Dice above six can't be drawn with pips, so the whole set uses digits
+	sides := max(config.DiceSides, slices.Max(p.Dice))
+	dieStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(b.theme.Border).
+		Align(lipgloss.Center).
+		Width(DICE_WIDTH).
+		Bold(true)
+
+	var dice []string
+	for _, value := range p.Dice {
+		dice = append(dice, dieStyle.Render(b.dieFace(value, sides)))
+	}
+
+	var targets []string
+	for _, target := range p.Targets {
+		if best, found := b.progress.Best(b.pack, p, target); found {
+			targets = append(targets, fmt.Sprintf("[x] %2d  best: %s", target, best))
+		} else {
+			targets = append(targets, fmt.Sprintf("[ ] %2d", target))
+		}
+	}
+
+	rules := "Use every die exactly once to hit each target."
+	if len(p.Constraints) > 0 {
+		names := make([]string, len(p.Constraints))
+		for i, c := range p.Constraints {
+			names[i] = string(c)
+		}
+		rules += "\nConstraints: " + strings.Join(names, ", ")
+	}
+
+	sections := []string{
+		rules,
+		lipgloss.JoinHorizontal(lipgloss.Top, dice...),
+		strings.Join(targets, "\n"),
+		b.textInput.View(),
+	}
+	if b.notice != "" {
+		sections = append(sections, lipgloss.NewStyle().Foreground(b.theme.Instructions).Render(b.notice))
+	}
+	if b.debug != "" {
+		sections = append(sections, lipgloss.NewStyle().Foreground(b.theme.Error).Render(b.debug))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprint(value)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/game"
+	"dicer/pkg/models"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+/*************************************
+* Saved Games
+*************************************/
+// savedGame is the game itself, with what the UI needs to carry on
+type savedGame struct {
+	Game     *game.Game       `json:"game"`
+	Phase    models.TurnPhase `json:"phase"`
+	History  []string         `json:"history"`
+	Campaign *savedCampaign   `json:"campaign,omitempty"`
+}
+
+// savedCampaign is where a campaign run had got to
+type savedCampaign struct {
+	Seed    uint64              `json:"seed"`
+	Level   int                 `json:"level"`
+	Cleared []game.LevelSummary `json:"cleared"`
+}
+
+func savePath() string {
+	return filepath.Join(config.StateDir(), "save.json")
+}
+
+// saveGame writes the game in progress so it can be picked up with --resume
+func (m *model) saveGame() (string, error) {
+	phase, err := m.getCurrentState()
+	if err != nil {
+		return "", err
+	}
+
+	saved := savedGame{Game: m.game, Phase: phase, History: m.history}
+
+	if m.campaign != nil {
+		saved.Campaign = &savedCampaign{Seed: m.campaign.Seed, Level: m.campaign.Level, Cleared: m.campaign.Cleared}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := savePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// loadGame restores the game saved at path into m
+func (m *model) loadGame(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var saved savedGame
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+
+	if saved.Game == nil || saved.Game.Turn == nil || saved.Game.Player.Ailments == nil {
+		return errors.New("saved game is from an older version and can't be resumed")
+	}
+
+	// A campaign run picks up on the level it was saved on
+	if saved.Campaign != nil {
+		m.campaign = game.RestoreCampaign(saved.Campaign.Seed, saved.Campaign.Level, saved.Campaign.Cleared)
+		m.game = m.campaign.Game
+	}
+
+	// Rebuild the phase stack by working through it until the saved phase is on top
+	turn := saved.Game.Turn
+	turn.Stack = models.CreateTurnStack()
+	if saved.Phase == models.GS_GameOver {
+		turn.Stack.Push(models.GS_GameOver)
+	}
+	for top, err := turn.Stack.Top(); top != saved.Phase; top, err = turn.Stack.Top() {
+		if err != nil {
+			return errors.New("saved game has an unknown phase")
+		}
+		turn.Stack.Pop()
+	}
+
+	// Carry on rolling from this session's source, keeping the campaign's
+	// pointer to the game
+	saved.Game.Source = m.game.Source
+	turn.Source = m.game.Source
+	*m.game = *saved.Game
+
+	m.syncChoices()
+	m.history = saved.History
+	m.historyIndex = len(saved.History)
+
+	return nil
+}
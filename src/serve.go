@@ -0,0 +1,39 @@
+package main
+
+import (
+	"dicer/pkg/api"
+	"dicer/pkg/logger"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+/*************************************
+* HTTP Server
+*************************************/
+// runServe hosts games over HTTP for other frontends to play against
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("dicer serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	maxGames := flags.Int("max-games", api.DEFAULT_MAX_GAMES, "most games to keep at once, or 0 for no limit")
+	idleTTL := flags.Duration("idle-ttl", api.DEFAULT_IDLE_TTL, "drop games left alone this long, or 0 to keep them")
+	logOptions := addLogFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	logOpts, err := logOptions(os.Stderr)
+	if err != nil {
+		return err
+	}
+	closeLog, err := logger.Setup(logOpts)
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	fmt.Printf("Serving games on http://%s\n", *addr)
+	return http.ListenAndServe(*addr, api.NewServerWithLimits(*maxGames, *idleTTL))
+}
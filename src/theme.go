@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*************************************
+* Themes
+*************************************/
+type Theme struct {
+	Name             string
+	Border           lipgloss.TerminalColor
+	Highlight        lipgloss.TerminalColor
+	LogoPrefix       lipgloss.TerminalColor
+	LogoSuffix       lipgloss.TerminalColor
+	Text             lipgloss.TerminalColor
+	Lives            lipgloss.TerminalColor
+	Turn             lipgloss.TerminalColor
+	Items            lipgloss.TerminalColor
+	Error            lipgloss.TerminalColor
+	Instructions     lipgloss.TerminalColor
+	AilmentActive    lipgloss.TerminalColor
+	AilmentInactive  lipgloss.TerminalColor
+	AilmentCursed    lipgloss.TerminalColor
+	AilmentSpreading lipgloss.TerminalColor
+	// Monochrome themes rely on reverse video instead of background colors
+	Monochrome bool
+}
+
+const (
+	THEME_AUTO = "auto"
+	THEME_NONE = "none"
+)
+
+var darkTheme = Theme{
+	Name:             "dark",
+	Border:           lipgloss.Color("#555555"),
+	Highlight:        lipgloss.Color("#FFFF00"),
+	LogoPrefix:       lipgloss.Color("#9FE2BF"),
+	LogoSuffix:       lipgloss.Color("#87CEEB"),
+	Text:             lipgloss.Color("#EAEAEA"),
+	Lives:            lipgloss.Color("#963c31"),
+	Turn:             lipgloss.Color("#45657A"),
+	Items:            lipgloss.Color("#6A5A8C"),
+	Error:            lipgloss.Color("#C24D3F"),
+	Instructions:     lipgloss.Color("#D9C380"),
+	AilmentActive:    lipgloss.Color("#56787a"),
+	AilmentInactive:  lipgloss.Color("#333333"),
+	AilmentCursed:    lipgloss.Color("#7A3B56"),
+	AilmentSpreading: lipgloss.Color("#7A6A2E"),
+}
+
+var lightTheme = Theme{
+	Name:             "light",
+	Border:           lipgloss.Color("#AAAAAA"),
+	Highlight:        lipgloss.Color("#C77700"),
+	LogoPrefix:       lipgloss.Color("#2E8B57"),
+	LogoSuffix:       lipgloss.Color("#1F6F9F"),
+	Text:             lipgloss.Color("#1F1F1F"),
+	Lives:            lipgloss.Color("#F2B8B0"),
+	Turn:             lipgloss.Color("#B7D3E6"),
+	Items:            lipgloss.Color("#D3C8EA"),
+	Error:            lipgloss.Color("#A3271B"),
+	Instructions:     lipgloss.Color("#7A5C00"),
+	AilmentActive:    lipgloss.Color("#A8D5D6"),
+	AilmentInactive:  lipgloss.Color("#D5D5D5"),
+	AilmentCursed:    lipgloss.Color("#E3A6B8"),
+	AilmentSpreading: lipgloss.Color("#E6D58F"),
+}
+
+var highContrastTheme = Theme{
+	Name:             "high-contrast",
+	Border:           lipgloss.Color("#FFFFFF"),
+	Highlight:        lipgloss.Color("#FFFF00"),
+	LogoPrefix:       lipgloss.Color("#00FF00"),
+	LogoSuffix:       lipgloss.Color("#00FFFF"),
+	Text:             lipgloss.Color("#FFFFFF"),
+	Lives:            lipgloss.Color("#CC0000"),
+	Turn:             lipgloss.Color("#0000CC"),
+	Items:            lipgloss.Color("#6600CC"),
+	Error:            lipgloss.Color("#FF5555"),
+	Instructions:     lipgloss.Color("#FFFF00"),
+	AilmentActive:    lipgloss.Color("#007700"),
+	AilmentInactive:  lipgloss.Color("#000000"),
+	AilmentCursed:    lipgloss.Color("#AA00AA"),
+	AilmentSpreading: lipgloss.Color("#AA5500"),
+}
+
+// Okabe-Ito palette, distinguishable with the common forms of colorblindness
+var colorblindTheme = Theme{
+	Name:             "colorblind",
+	Border:           lipgloss.Color("#999999"),
+	Highlight:        lipgloss.Color("#F0E442"),
+	LogoPrefix:       lipgloss.Color("#009E73"),
+	LogoSuffix:       lipgloss.Color("#56B4E9"),
+	Text:             lipgloss.Color("#FFFFFF"),
+	Lives:            lipgloss.Color("#D55E00"),
+	Turn:             lipgloss.Color("#0072B2"),
+	Items:            lipgloss.Color("#009E73"),
+	Error:            lipgloss.Color("#E69F00"),
+	Instructions:     lipgloss.Color("#F0E442"),
+	AilmentActive:    lipgloss.Color("#0072B2"),
+	AilmentInactive:  lipgloss.Color("#333333"),
+	AilmentCursed:    lipgloss.Color("#CC79A7"),
+	AilmentSpreading: lipgloss.Color("#E69F00"),
+}
+
+// Used when NO_COLOR is set
+var noColorTheme = Theme{
+	Name:             THEME_NONE,
+	Border:           lipgloss.NoColor{},
+	Highlight:        lipgloss.NoColor{},
+	LogoPrefix:       lipgloss.NoColor{},
+	LogoSuffix:       lipgloss.NoColor{},
+	Text:             lipgloss.NoColor{},
+	Lives:            lipgloss.NoColor{},
+	Turn:             lipgloss.NoColor{},
+	Items:            lipgloss.NoColor{},
+	Error:            lipgloss.NoColor{},
+	Instructions:     lipgloss.NoColor{},
+	AilmentActive:    lipgloss.NoColor{},
+	AilmentInactive:  lipgloss.NoColor{},
+	AilmentCursed:    lipgloss.NoColor{},
+	AilmentSpreading: lipgloss.NoColor{},
+	Monochrome:       true,
+}
+
+var builtinThemes = map[string]Theme{
+	darkTheme.Name:         darkTheme,
+	lightTheme.Name:        lightTheme,
+	highContrastTheme.Name: highContrastTheme,
+	colorblindTheme.Name:   colorblindTheme,
+	noColorTheme.Name:      noColorTheme,
+}
+
+// selectTheme picks the theme called name from the built-in and user themes.
+// NO_COLOR always wins, and "auto" follows the terminal's background.
+func selectTheme(name string, userThemes map[string]map[string]string) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return noColorTheme, nil
+	}
+
+	if name == "" || name == THEME_AUTO {
+		if lipgloss.HasDarkBackground() {
+			return darkTheme, nil
+		}
+		return lightTheme, nil
+	}
+
+	if values, ok := userThemes[name]; ok {
+		return parseUserTheme(name, values)
+	}
+
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+
+	return Theme{}, fmt.Errorf("unknown theme %q, expected one of: %s", name, strings.Join(themeNames(userThemes), ", "))
+}
+
+// parseUserTheme builds a theme from a [themes.<name>] table. Colors that
+// aren't set are taken from the theme named by "base", or dark by default.
+func parseUserTheme(name string, values map[string]string) (Theme, error) {
+	theme := darkTheme
+	if baseName, ok := values["base"]; ok {
+		base, found := builtinThemes[baseName]
+		if !found {
+			return Theme{}, fmt.Errorf("theme %q: unknown base theme %q", name, baseName)
+		}
+		theme = base
+	}
+	theme.Name = name
+
+	fields := map[string]*lipgloss.TerminalColor{
+		"border":            &theme.Border,
+		"highlight":         &theme.Highlight,
+		"logo_prefix":       &theme.LogoPrefix,
+		"logo_suffix":       &theme.LogoSuffix,
+		"text":              &theme.Text,
+		"lives":             &theme.Lives,
+		"turn":              &theme.Turn,
+		"items":             &theme.Items,
+		"error":             &theme.Error,
+		"instructions":      &theme.Instructions,
+		"ailment_active":    &theme.AilmentActive,
+		"ailment_inactive":  &theme.AilmentInactive,
+		"ailment_cursed":    &theme.AilmentCursed,
+		"ailment_spreading": &theme.AilmentSpreading,
+	}
+
+	for key, value := range values {
+		if key == "base" {
+			continue
+		}
+
+		field, ok := fields[key]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %q: unknown color %q", name, key)
+		}
+		if !isColor(value) {
+			return Theme{}, fmt.Errorf("theme %q: %s is %q, expected a hex color like \"#FF8800\" or an ANSI color from 0 to 255", name, key, value)
+		}
+		*field = lipgloss.Color(value)
+	}
+
+	return theme, nil
+}
+
+// isColor reports whether value is a hex color such as "#FF8800" or "#F80",
+// or an ANSI color number
+func isColor(value string) bool {
+	if hex, ok := strings.CutPrefix(value, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+
+	number, err := strconv.Atoi(value)
+	return err == nil && number >= 0 && number <= 255
+}
+
+// newHelp styles the key binding footer to match theme
+func newHelp(theme Theme) help.Model {
+	h := help.New()
+	h.Styles.ShortKey = lipgloss.NewStyle().Foreground(theme.Instructions).Bold(true)
+	h.Styles.ShortDesc = lipgloss.NewStyle().Foreground(theme.Instructions)
+	h.Styles.ShortSeparator = lipgloss.NewStyle().Foreground(theme.Border)
+	return h
+}
+
+func themeNames(userThemes map[string]map[string]string) []string {
+	names := []string{THEME_AUTO}
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	for name := range userThemes {
+		if _, isBuiltin := builtinThemes[name]; !isBuiltin {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names[1:])
+	return names
+}
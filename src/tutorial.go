@@ -0,0 +1,166 @@
+package main
+
+import (
+	"dicer/pkg/config"
+	"dicer/pkg/math"
+	"dicer/pkg/models"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+/*************************************
+* Tutorial
+*************************************/
+// The tutorial plays on these dice, in order, so every new player sees the
+// same game. The fifth value is the first re-roll.
+var tutorialDice = []int{6, 2, 1, 6, 3, 4, 4, 2, 1, 5, 6, 3, 3, 1, 2, 2, 5}
+
+// Hints stop after this round and the player finishes the game on their own
+const TUTORIAL_ROUNDS = 2
+
+// tutorialHint explains what to do in the current phase of the tutorial game
+func (m model) tutorialHint(state models.TurnPhase) string {
+	if m.game.Round > TUTORIAL_ROUNDS {
+		if state == models.GS_TurnStart && m.game.Round == TUTORIAL_ROUNDS+1 {
+			return "That's everything! Finish the game on your own, or open the menu with esc to read the rules."
+		}
+		return ""
+	}
+
+	switch state {
+	case models.GS_TurnStart:
+		if m.game.Round == 1 {
+			return fmt.Sprintf("Welcome to Dicer! Clear every ailment in the bar at the bottom to win. Each turn starts with a roll of the dice. Press %s to roll.", m.keys.Roll.Help().Key)
+		}
+		return "A new turn brings fresh dice. Roll again."
+
+	case models.GS_RollPhase:
+		if m.game.Round == 1 {
+			return fmt.Sprintf("You can re-roll any of your dice once per turn. Two sixes are hard to use, so move to the last die with %s and press %s to mark it, then %s to re-roll.",
+				m.keys.Right.Help().Key, m.keys.Select.Help().Key, m.keys.Confirm.Help().Key)
+		}
+		return fmt.Sprintf("Happy with these? Press %s without marking anything to keep them all.", m.keys.Confirm.Help().Key)
+
+	case models.GS_ExpressionPhase:
+		hint := "Now use every die exactly once with + - * / and parentheses, putting a space between each character."
+		if m.suggestion != "" {
+			hint += " Try: " + m.suggestion
+		}
+		return hint
+
+	case models.GS_ResultsPhase:
+		if m.game.Turn.RemovedAilment {
+			return "Hit! That ailment is gone for good. Clear them all to win."
+		}
+		return fmt.Sprintf("That missed every ailment, so it cost a life. Lose all %d and the game is over.", config.MaxLives)
+	}
+
+	return ""
+}
+
+// updateSuggestion works out the tutorial's suggestion once, as the phase
+// changes, so the solver doesn't run on every render
+func (m *model) updateSuggestion(state models.TurnPhase) {
+	m.suggestion = ""
+	if m.tutorial && state == models.GS_ExpressionPhase && m.game.Round <= TUTORIAL_ROUNDS {
+		m.suggestion = m.suggestExpression()
+	}
+}
+
+// suggestExpression finds an expression that hits the highest ailment left
+func (m model) suggestExpression() string {
+	solutions := math.Solve(m.diceValues())
+
+	values := m.game.Player.Ailments.Values()
+	slices.Sort(values)
+	for _, num := range slices.Backward(values) {
+		if exp, ok := solutions[num]; ok {
+			return fmt.Sprintf("%s = %d", exp, num)
+		}
+	}
+	return ""
+}
+
+func (m model) getTutorialHint(width int) string {
+	state, _ := m.getCurrentState()
+	hint := m.tutorialHint(state)
+	if hint == "" {
+		return ""
+	}
+
+	// Width doesn't include the border
+	style := lipgloss.NewStyle().
+		Width(width-2).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.theme.Highlight).
+		Padding(0, 1)
+
+	return style.Render("Tutorial: " + hint)
+}
+
+/*************************************
+* Rules
+*************************************/
+type rulesPage struct {
+	title string
+	body  string
+}
+
+var rulesPages = []rulesPage{
+	{"Goal", strings.Join([]string{
+		"The numbered boxes along the bottom are your ailments.",
+		"Remove every one of them before you run out of lives.",
+	}, "\n")},
+	{"Rolling", strings.Join([]string{
+		"Each turn starts by rolling four dice.",
+		"You can then pick any of them to re-roll, once.",
+	}, "\n")},
+	{"Expressions", strings.Join([]string{
+		"Combine every die exactly once using + - * / and",
+		"parentheses, with a space between each character:",
+		"",
+		"    ( 6 - 3 ) * 2 - 1",
+		"",
+		"Division drops any remainder, rounding towards zero.",
+		"Press tab to build the expression from the dice instead.",
+	}, "\n")},
+	{"Results", strings.Join([]string{
+		"If the result matches a remaining ailment, it's removed.",
+		"Anything else costs a life.",
+		"",
+		"If no expression can reach an ailment, press ctrl+x to",
+		"challenge the roll. A right challenge skips the turn, a",
+		"wrong one shows an expression that works and costs a life.",
+		"",
+		"Clear every ailment to win. Run out of lives and you lose.",
+	}, "\n")},
+	{"Combos", strings.Join([]string{
+		"Split the dice between several expressions with commas",
+		"to hit more than one ailment in a turn:",
+		"",
+		"    3 + 1 , 6 / 2",
+		"",
+		"Each die is still used exactly once. Every part that hits",
+		"multiplies your points, but if any part misses you lose a life.",
+		"",
+		"Repeating last turn's solution scores no points, even if",
+		"you shuffle it around: 3 + 4 and 4 + 3 count as the same.",
+	}, "\n")},
+	{"Policies", strings.Join([]string{
+		"Some rule sets change what a miss costs:",
+		"",
+		"    nearmiss  missing an ailment by 1 costs nothing",
+		"    bust      going over the highest ailment costs 2 lives",
+		"    pass      a wrong challenge costs 2 lives",
+	}, "\n")},
+	{"Controls", strings.Join([]string{
+		"The footer always shows the keys you can use.",
+		"You can also click dice, buttons and ailments.",
+		"Clicking an ailment tells you if the dice can reach it.",
+		"Press esc at any time for the menu.",
+		"Press F12 or ~ to show what the game is doing under the hood.",
+	}, "\n")},
+}
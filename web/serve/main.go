@@ -0,0 +1,28 @@
+// Command serve hosts the browser version of dicer for local testing. Build
+// the wasm binary first, as described in web/wasm, then run
+//
+//	go run ./web/serve
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8081", "address to listen on")
+	dir := flag.String("dir", "web/static", "directory to serve")
+	flag.Parse()
+
+	if _, err := os.Stat(*dir + "/dicer.wasm"); err != nil {
+		fmt.Printf("%s/dicer.wasm is missing, build it with GOOS=js GOARCH=wasm go build -o %s/dicer.wasm ./web/wasm\n", *dir, *dir)
+	}
+
+	fmt.Printf("Serving %s on http://%s\n", *dir, *addr)
+	if err := http.ListenAndServe(*addr, http.FileServer(http.Dir(*dir))); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+// Thin frontend over the wasm engine, which exposes window.dicer once loaded.
+// Every engine call returns the game state as JSON.
+const selected = new Set();
+let state = null;
+
+const $ = (id) => document.getElementById(id);
+
+function apply(json) {
+  state = JSON.parse(json);
+  if (state.phase !== "roll") {
+    selected.clear();
+  }
+  render();
+}
+
+function render() {
+  $("status").textContent = `Turn ${state.round} · Lives ${state.lives} · Score ${state.score} · Next: ${state.next}`;
+
+  const ailments = $("ailments");
+  ailments.replaceChildren();
+  for (const ailment of state.ailmentDetails) {
+    const box = document.createElement("span");
+    box.className = ailment.removed ? "ailment removed" : `ailment ${ailment.kind}`;
+    box.textContent = ailmentLabel(ailment);
+    ailments.append(box);
+  }
+
+  const dice = $("dice");
+  dice.replaceChildren();
+  state.dice.forEach((value, i) => {
+    const die = document.createElement("button");
+    die.className = selected.has(i + 1) ? "die selected" : "die";
+    die.textContent = value;
+    die.disabled = state.phase !== "roll";
+    die.onclick = () => {
+      selected.has(i + 1) ? selected.delete(i + 1) : selected.add(i + 1);
+      render();
+    };
+    dice.append(die);
+  });
+
+  $("roll").disabled = state.phase !== "start";
+  $("reroll").disabled = state.phase !== "roll";
+  $("submit").disabled = state.phase !== "expression";
+  $("expression").disabled = state.phase !== "expression";
+  $("challenge").disabled = state.phase !== "expression";
+  $("continue").disabled = state.phase !== "results";
+
+  let message = (state.spread || []).map((num) => `An ailment spread to ${num}! `).join("");
+  if (state.challenged) {
+    message = state.reveal ? `Challenge failed: ${state.reveal} would have hit. You lost a life.` : "Challenge upheld! The turn is skipped.";
+  } else if (state.parts && state.parts.length > 1) {
+    message = state.parts.map((part) => `${part.expression} = ${part.result} ${part.hit ? "hit" : "missed"}. `).join("");
+    message += state.lostLife ? "You lost a life." : "";
+  } else if (state.result !== undefined) {
+    message = `${state.expression} = ${state.result}. `;
+    if (state.cursed) {
+      message += "Cursed! You lost a life.";
+    } else if (state.lostLife) {
+      message += "You lost a life.";
+    } else if (state.removedAilment) {
+      message += "Hit!";
+    } else {
+      message += "Hit, but it's still there.";
+    }
+  }
+  if (state.phase === "over") {
+    message += state.won ? " You win!" : " You lose!";
+  }
+  $("message").textContent = message;
+  $("error").textContent = state.error || "";
+}
+
+// ailmentLabel matches the TUI: hits still needed, a curse, or turns until spreading
+function ailmentLabel(ailment) {
+  if (ailment.removed) {
+    return `${ailment.value}`;
+  }
+  if (ailment.hits > 1) {
+    return `${ailment.value} x${ailment.hits}`;
+  }
+  if (ailment.kind === "cursed") {
+    return `${ailment.value} !`;
+  }
+  if (ailment.kind === "spreading") {
+    return `${ailment.value} ~${ailment.spread}`;
+  }
+  return `${ailment.value}`;
+}
+
+$("roll").onclick = () => apply(dicer.roll());
+$("reroll").onclick = () => apply(dicer.reroll([...selected]));
+$("submit").onclick = () => {
+  apply(dicer.submit($("expression").value));
+  if (!state.error) {
+    $("expression").value = "";
+  }
+};
+$("expression").onkeydown = (event) => {
+  if (event.key === "Enter") {
+    $("submit").click();
+  }
+};
+$("challenge").onclick = () => apply(dicer.challenge());
+$("continue").onclick = () => apply(dicer.continue());
+$("new").onclick = () => apply(dicer.newGame());
+
+window.addEventListener("dicerready", () => apply(dicer.state()));
+
+const go = new Go();
+WebAssembly.instantiateStreaming(fetch("dicer.wasm"), go.importObject)
+  .then((result) => go.run(result.instance));
@@ -0,0 +1,107 @@
+//go:build js && wasm
+
+// Command wasm runs the dicer rules engine in a browser. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o web/static/dicer.wasm ./web/wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" web/static/
+//
+// and serve web/static with go run ./web/serve.
+package main
+
+import (
+	"dicer/pkg/game"
+	"dicer/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+/*************************************
+* Bridge
+*************************************/
+// Every bridge function returns the game as a JSON string, along with the
+// error from the action if there was one.
+type bridgeResponse struct {
+	game.State
+	Error    string `json:"error,omitempty"`
+	ErrorPos *int   `json:"errorPos,omitempty"`
+}
+
+var current *game.Game
+
+func main() {
+	current = game.CreateGame(models.RandomSource{})
+
+	dicer := js.Global().Get("Object").New()
+	dicer.Set("newGame", js.FuncOf(newGame))
+	dicer.Set("state", js.FuncOf(func(js.Value, []js.Value) any { return respond(nil) }))
+	dicer.Set("roll", js.FuncOf(func(js.Value, []js.Value) any { return respond(current.Roll()) }))
+	dicer.Set("reroll", js.FuncOf(reroll))
+	dicer.Set("submit", js.FuncOf(submit))
+	dicer.Set("challenge", js.FuncOf(func(js.Value, []js.Value) any { return respond(current.Challenge()) }))
+	dicer.Set("continue", js.FuncOf(func(js.Value, []js.Value) any { return respond(current.NextTurn()) }))
+	js.Global().Set("dicer", dicer)
+
+	// Tell the page the engine is ready, then keep the functions alive
+	js.Global().Call("dispatchEvent", js.Global().Get("Event").New("dicerready"))
+	select {}
+}
+
+// newGame(seed?) starts over, repeating the same rolls for the same seed
+func newGame(this js.Value, args []js.Value) any {
+	var source models.DiceSource = models.RandomSource{}
+	if len(args) > 0 && args[0].Type() == js.TypeNumber && args[0].Int() > 0 {
+		source = models.CreateSeededSource(uint64(args[0].Int()))
+	}
+
+	current = game.CreateGame(source)
+	return respond(nil)
+}
+
+// reroll(positions) re-rolls the dice at the given positions, counted from 1
+func reroll(this js.Value, args []js.Value) any {
+	// Bad arguments are reported rather than left to panic, which would stop
+	// the engine for good
+	selected := make(map[int]struct{})
+	if len(args) > 0 && !args[0].IsUndefined() && !args[0].IsNull() {
+		positions := args[0]
+		if positions.Type() != js.TypeObject || !js.Global().Get("Array").Call("isArray", positions).Bool() {
+			return respond(errors.New("reroll needs an array of die positions"))
+		}
+		for i := 0; i < positions.Length(); i++ {
+			position := positions.Index(i)
+			if position.Type() != js.TypeNumber {
+				return respond(fmt.Errorf("die positions must be numbers, got a %s", position.Type()))
+			}
+			if position.Float() != math.Trunc(position.Float()) {
+				return respond(fmt.Errorf("die positions must be whole numbers, got %g", position.Float()))
+			}
+			selected[position.Int()-1] = struct{}{}
+		}
+	}
+	return respond(current.Reroll(selected))
+}
+
+// submit(expression)
+func submit(this js.Value, args []js.Value) any {
+	if len(args) == 0 {
+		return respond(errors.New("submit needs an expression"))
+	}
+	return respond(current.Submit(args[0].String()))
+}
+
+func respond(err error) any {
+	resp := bridgeResponse{State: current.State()}
+	if err != nil {
+		resp.Error = err.Error()
+		var expErr *game.ExpressionError
+		if errors.As(err, &expErr) {
+			resp.ErrorPos = &expErr.Pos
+		}
+	}
+
+	data, _ := json.Marshal(resp)
+	return string(data)
+}